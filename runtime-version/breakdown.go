@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/oasisprotocol/oasis-core/go/common/cbor"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/sgx"
+	"github.com/oasisprotocol/oasis-core/go/common/sgx/ias"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+const (
+	teeNone        = "none"
+	enclaveUnknown = "undecodable"
+)
+
+// breakdownStats is the per-version breakdown of the nodes' roles and
+// TEE capabilities.
+type breakdownStats struct {
+	// RegisteredVersion is the version in the runtime descriptor, and
+	// ExpectedEnclaves are the enclave identities (MRENCLAVE || MRSIGNER)
+	// allowed for it, if the runtime requires SGX.
+	RegisteredVersion string   `json:"registered_version" yaml:"registered_version"`
+	ExpectedEnclaves  []string `json:"expected_enclaves,omitempty" yaml:"expected_enclaves,omitempty"`
+
+	Versions []*versionBreakdown `json:"versions" yaml:"versions"`
+
+	// UnexpectedEnclaves are the nodes running the registered version
+	// in an enclave with an identity that is not allowed for it.
+	UnexpectedEnclaves []*unexpectedEnclave `json:"unexpected_enclaves" yaml:"unexpected_enclaves"`
+}
+
+// versionBreakdown is the breakdown of the nodes running a version.
+type versionBreakdown struct {
+	Version  string          `json:"version" yaml:"version"`
+	Roles    map[string]int  `json:"roles" yaml:"roles"`
+	TEE      map[string]int  `json:"tee" yaml:"tee"`
+	Enclaves []*enclaveStats `json:"enclaves,omitempty" yaml:"enclaves,omitempty"`
+}
+
+// enclaveStats is the number of nodes running in an SGX enclave.
+type enclaveStats struct {
+	MrEnclave string `json:"mr_enclave" yaml:"mr_enclave"`
+	MrSigner  string `json:"mr_signer" yaml:"mr_signer"`
+	Nodes     int    `json:"nodes" yaml:"nodes"`
+
+	// Expected is set iff the version is the registered version.
+	Expected *bool `json:"expected,omitempty" yaml:"expected,omitempty"`
+}
+
+// unexpectedEnclave is a node running in an unexpected enclave.
+type unexpectedEnclave struct {
+	ID        string `json:"id" yaml:"id"`
+	Entity    string `json:"entity" yaml:"entity"`
+	MrEnclave string `json:"mr_enclave" yaml:"mr_enclave"`
+	MrSigner  string `json:"mr_signer" yaml:"mr_signer"`
+}
+
+// decodeEnclaveIdentity returns the MRENCLAVE and MRSIGNER from the
+// node's SGX attestation.  The attestation is not verified.
+func decodeEnclaveIdentity(tee *node.CapabilityTEE) (*sgx.EnclaveIdentity, error) {
+	if tee.Hardware != node.TEEHardwareIntelSGX {
+		return nil, fmt.Errorf("unsupported TEE hardware: %s", tee.Hardware)
+	}
+
+	var bundle ias.AVRBundle
+	if err := cbor.Unmarshal(tee.Attestation, &bundle); err != nil {
+		return nil, fmt.Errorf("malformed AVR bundle: %w", err)
+	}
+	var avr ias.AttestationVerificationReport
+	if err := json.Unmarshal(bundle.Body, &avr); err != nil {
+		return nil, fmt.Errorf("malformed AVR: %w", err)
+	}
+	quote, err := avr.Quote()
+	if err != nil {
+		return nil, fmt.Errorf("malformed quote: %w", err)
+	}
+
+	return &sgx.EnclaveIdentity{
+		MrEnclave: quote.Report.MRENCLAVE,
+		MrSigner:  quote.Report.MRSIGNER,
+	}, nil
+}
+
+// expectedEnclaves returns the enclave identities allowed for the
+// registered version of the runtime, or nil if it does not require SGX.
+func expectedEnclaves(rt *registryAPI.Runtime) (map[sgx.EnclaveIdentity]bool, error) {
+	if rt.TEEHardware != node.TEEHardwareIntelSGX {
+		return nil, nil
+	}
+
+	var cs sgx.Constraints
+	if err := cbor.Unmarshal(rt.Version.TEE, &cs); err != nil {
+		return nil, fmt.Errorf("malformed SGX constraints: %w", err)
+	}
+	expected := make(map[sgx.EnclaveIdentity]bool)
+	for _, id := range cs.Enclaves {
+		expected[id] = true
+	}
+	return expected, nil
+}
+
+// applyBreakdown adds the breakdown of the active nodes' roles and TEE
+// capabilities to the report.
+func (r *runtimeReport) applyBreakdown(rt *registryAPI.Runtime, nodes []*node.Node, states nodeStates) error {
+	expected, err := expectedEnclaves(rt)
+	if err != nil {
+		return fmt.Errorf("runtime %s: %w", rt.ID, err)
+	}
+
+	b := &breakdownStats{
+		RegisteredVersion:  rt.Version.Version.String(),
+		UnexpectedEnclaves: []*unexpectedEnclave{},
+	}
+	for id := range expected {
+		b.ExpectedEnclaves = append(b.ExpectedEnclaves, id.String())
+	}
+	sort.Strings(b.ExpectedEnclaves)
+
+	byVersion := make(map[string]*versionBreakdown)
+	enclaves := make(map[string]map[sgx.EnclaveIdentity]*enclaveStats)
+	for _, v := range r.Versions {
+		vb := &versionBreakdown{
+			Version: v.Version,
+			Roles:   make(map[string]int),
+			TEE:     make(map[string]int),
+		}
+		b.Versions = append(b.Versions, vb)
+		byVersion[v.Version] = vb
+		enclaves[v.Version] = make(map[sgx.EnclaveIdentity]*enclaveStats)
+	}
+
+	for _, n := range nodes {
+		if states.get(n.ID) != nodeActive {
+			continue
+		}
+		for _, nrt := range n.Runtimes {
+			if nrt.ID != rt.ID {
+				continue
+			}
+			version := nrt.Version.String()
+			vb := byVersion[version]
+
+			for _, role := range node.Roles() {
+				if n.HasRoles(role) {
+					vb.Roles[role.String()]++
+				}
+			}
+
+			tee := nrt.Capabilities.TEE
+			if tee == nil {
+				vb.TEE[teeNone]++
+				continue
+			}
+			vb.TEE[tee.Hardware.String()]++
+			if tee.Hardware != node.TEEHardwareIntelSGX {
+				continue
+			}
+
+			var id sgx.EnclaveIdentity
+			if decoded, err := decodeEnclaveIdentity(tee); err == nil {
+				id = *decoded
+			}
+			es, ok := enclaves[version][id]
+			if !ok {
+				es = &enclaveStats{
+					MrEnclave: id.MrEnclave.String(),
+					MrSigner:  id.MrSigner.String(),
+				}
+				if id == (sgx.EnclaveIdentity{}) {
+					es.MrEnclave, es.MrSigner = enclaveUnknown, enclaveUnknown
+				}
+				if version == b.RegisteredVersion && expected != nil {
+					isExpected := expected[id]
+					es.Expected = &isExpected
+				}
+				enclaves[version][id] = es
+				vb.Enclaves = append(vb.Enclaves, es)
+			}
+			es.Nodes++
+
+			if es.Expected != nil && !*es.Expected {
+				b.UnexpectedEnclaves = append(b.UnexpectedEnclaves, &unexpectedEnclave{
+					ID:        n.ID.String(),
+					Entity:    staking.NewAddress(n.EntityID).String(),
+					MrEnclave: es.MrEnclave,
+					MrSigner:  es.MrSigner,
+				})
+			}
+		}
+	}
+	for _, vb := range b.Versions {
+		sortEnclaves(vb.Enclaves)
+	}
+	sortUnexpectedEnclaves(b.UnexpectedEnclaves)
+
+	r.Breakdown = b
+	return nil
+}
+
+// sortEnclaves sorts the enclaves by the number of nodes (descending),
+// breaking ties on the enclave identity, so that the output is stable
+// across runs.
+func sortEnclaves(enclaves []*enclaveStats) {
+	sort.Slice(enclaves, func(i, j int) bool {
+		a, b := enclaves[i], enclaves[j]
+		switch {
+		case a.Nodes != b.Nodes:
+			return a.Nodes > b.Nodes
+		case a.MrEnclave != b.MrEnclave:
+			return a.MrEnclave < b.MrEnclave
+		default:
+			return a.MrSigner < b.MrSigner
+		}
+	})
+}
+
+// sortUnexpectedEnclaves sorts the nodes running in an unexpected enclave
+// by entity, and then node ID, so that the output is stable across runs.
+func sortUnexpectedEnclaves(nodes []*unexpectedEnclave) {
+	sort.Slice(nodes, func(i, j int) bool {
+		a, b := nodes[i], nodes[j]
+		if a.Entity != b.Entity {
+			return a.Entity < b.Entity
+		}
+		return a.ID < b.ID
+	})
+}
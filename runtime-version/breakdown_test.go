@@ -0,0 +1,65 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortEnclaves(t *testing.T) {
+	enclaves := []*enclaveStats{
+		{MrEnclave: "bb", MrSigner: "01", Nodes: 2},
+		{MrEnclave: "aa", MrSigner: "02", Nodes: 2},
+		{MrEnclave: "cc", MrSigner: "01", Nodes: 5},
+		{MrEnclave: "aa", MrSigner: "01", Nodes: 2},
+		{MrEnclave: enclaveUnknown, MrSigner: enclaveUnknown, Nodes: 1},
+	}
+	expected := []enclaveStats{
+		{MrEnclave: "cc", MrSigner: "01", Nodes: 5},
+		{MrEnclave: "aa", MrSigner: "01", Nodes: 2},
+		{MrEnclave: "aa", MrSigner: "02", Nodes: 2},
+		{MrEnclave: "bb", MrSigner: "01", Nodes: 2},
+		{MrEnclave: enclaveUnknown, MrSigner: enclaveUnknown, Nodes: 1},
+	}
+
+	// The order must not depend on the order the enclaves were seen in.
+	for i := 0; i < len(enclaves); i++ {
+		shuffled := append(append([]*enclaveStats{}, enclaves[i:]...), enclaves[:i]...)
+		sortEnclaves(shuffled)
+
+		var sorted []enclaveStats
+		for _, es := range shuffled {
+			sorted = append(sorted, *es)
+		}
+		if !reflect.DeepEqual(sorted, expected) {
+			t.Fatalf("rotation %d: expected %+v, got %+v", i, expected, sorted)
+		}
+	}
+}
+
+func TestSortUnexpectedEnclaves(t *testing.T) {
+	nodes := []*unexpectedEnclave{
+		{ID: "n2", Entity: "e1"},
+		{ID: "n1", Entity: "e2"},
+		{ID: "n1", Entity: "e1"},
+		{ID: "n3", Entity: "e0"},
+	}
+	expected := []unexpectedEnclave{
+		{ID: "n3", Entity: "e0"},
+		{ID: "n1", Entity: "e1"},
+		{ID: "n2", Entity: "e1"},
+		{ID: "n1", Entity: "e2"},
+	}
+
+	for i := 0; i < len(nodes); i++ {
+		shuffled := append(append([]*unexpectedEnclave{}, nodes[i:]...), nodes[:i]...)
+		sortUnexpectedEnclaves(shuffled)
+
+		var sorted []unexpectedEnclave
+		for _, n := range shuffled {
+			sorted = append(sorted, *n)
+		}
+		if !reflect.DeepEqual(sorted, expected) {
+			t.Fatalf("rotation %d: expected %+v, got %+v", i, expected, sorted)
+		}
+	}
+}
@@ -0,0 +1,916 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+)
+
+const (
+	formatText     = "text"
+	formatJSON     = "json"
+	formatCSV      = "csv"
+	formatYAML     = "yaml"
+	formatMarkdown = "markdown"
+)
+
+var reportFormats = []string{
+	formatText,
+	formatJSON,
+	formatCSV,
+	formatYAML,
+	formatMarkdown,
+}
+
+func validateFormat(format string) error {
+	for _, f := range reportFormats {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported format '%s' (valid: %s)", format, strings.Join(reportFormats, ", "))
+}
+
+// runtimesReport is the report on all runtimes.
+type runtimesReport struct {
+	Height   int64            `json:"height" yaml:"height"`
+	Runtimes []*runtimeReport `json:"runtimes" yaml:"runtimes"`
+}
+
+// writeReports writes the runtime reports.  Unless all is set, there
+// must be exactly one report, which is written on its own.
+func writeReports(w io.Writer, format string, height int64, reports []*runtimeReport, all bool) error {
+	var v interface{} = &runtimesReport{
+		Height:   height,
+		Runtimes: reports,
+	}
+	if !all {
+		v = reports[0]
+	}
+
+	switch format {
+	case formatText:
+		for i, r := range reports {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if err := writeReportText(w, r, all); err != nil {
+				return err
+			}
+		}
+		return nil
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatCSV:
+		return writeReportsCSV(w, reports)
+	case formatYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case formatMarkdown:
+		for i, r := range reports {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if err := writeReportMarkdown(w, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return validateFormat(format)
+	}
+}
+
+func writeReportText(w io.Writer, r *runtimeReport, showRuntime bool) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Runtime version stats for height: %d\n", r.Height)
+	if showRuntime {
+		fmt.Fprintf(&b, "Runtime: %s\n", r.runtimeDisplayName())
+	}
+
+	// Node version stats
+	fmt.Fprintln(&b, "\nTotal nodes:", r.TotalNodes)
+	for _, v := range r.Versions {
+		fmt.Fprintf(&b, "%s: %d\n", v.Version, v.Nodes)
+	}
+
+	// Excluded node version stats
+	for _, excluded := range []struct {
+		state    string
+		versions []*versionStats
+	}{
+		{"Expired", r.ExpiredVersions},
+		{"Frozen", r.FrozenVersions},
+	} {
+		if len(excluded.versions) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n%s nodes (excluded): %d\n", excluded.state, countNodes(excluded.versions))
+		for _, v := range excluded.versions {
+			fmt.Fprintf(&b, "%s: %d\n", v.Version, v.Nodes)
+		}
+	}
+
+	// Entities running target version
+	fmt.Fprintf(&b, "\nTotal entities running %s: %d\n", r.TargetVersion, len(r.TargetEntities))
+	for _, e := range r.Entities {
+		if e.isOnVersion(r.TargetVersion) {
+			fmt.Fprintln(&b, e.displayName())
+		}
+	}
+
+	// Stake-weighted adoption
+	if s := r.Stake; s != nil {
+		fmt.Fprintf(&b, "\nActive escrow, split over each entity's nodes (total: %s):\n", s.TotalEscrow)
+		for _, v := range s.Versions {
+			fmt.Fprintf(&b, "%s: %s (%s)\n", v.Version, v.Escrow, formatShare(v.Share))
+		}
+		fmt.Fprintf(&b, "\nUpgraded to %s: %s (%s) of active escrow, %d (%s) of validator voting power\n",
+			r.TargetVersion,
+			s.UpgradedEscrow,
+			formatShare(s.UpgradedShare),
+			s.UpgradedVotingPower,
+			formatShare(s.UpgradedVotingShare),
+		)
+	}
+
+	// Node role and TEE breakdown
+	if bd := r.Breakdown; bd != nil {
+		fmt.Fprintf(&b, "\nNode breakdown (registered version: %s):\n", bd.RegisteredVersion)
+		for _, v := range bd.Versions {
+			fmt.Fprintf(&b, "%s:\n", v.Version)
+			fmt.Fprintf(&b, "  roles: %s\n", formatCounts(v.Roles))
+			fmt.Fprintf(&b, "  tee: %s\n", formatCounts(v.TEE))
+			for _, e := range v.Enclaves {
+				fmt.Fprintf(&b, "  enclave %s/%s: %d%s\n", e.MrEnclave, e.MrSigner, e.Nodes, formatExpected(e.Expected, " (expected)", " (UNEXPECTED)"))
+			}
+		}
+		if len(bd.ExpectedEnclaves) > 0 {
+			fmt.Fprintf(&b, "\nNodes running %s in an unexpected enclave: %d\n", bd.RegisteredVersion, len(bd.UnexpectedEnclaves))
+			for _, n := range bd.UnexpectedEnclaves {
+				fmt.Fprintf(&b, "%s (entity %s): %s/%s\n", n.ID, n.Entity, n.MrEnclave, n.MrSigner)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeReportsCSV writes the reports as a single flat table, with a row
+// per version of each runtime, and a row per version run by each entity.
+func writeReportsCSV(w io.Writer, reports []*runtimeReport) error {
+	cw := csv.NewWriter(w)
+
+	_ = cw.Write([]string{"height", "runtime", "kind", "record", "version", "nodes", "address", "name", "target", "escrow", "escrow_share"})
+	for _, r := range reports {
+		writeReportCSV(cw, r)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeReportCSV(cw *csv.Writer, r *runtimeReport) {
+	height := strconv.FormatInt(r.Height, 10)
+	runtimeID := r.RuntimeID.String()
+
+	for i, v := range r.Versions {
+		var escrow, share string
+		if r.Stake != nil {
+			escrow = r.Stake.Versions[i].Escrow.String()
+			share = strconv.FormatFloat(r.Stake.Versions[i].Share, 'f', -1, 64)
+		}
+		_ = cw.Write([]string{
+			height,
+			runtimeID,
+			r.Kind,
+			"version",
+			v.Version,
+			strconv.Itoa(v.Nodes),
+			"",
+			"",
+			strconv.FormatBool(v.Version == r.TargetVersion),
+			escrow,
+			share,
+		})
+	}
+	for _, excluded := range []struct {
+		state    nodeState
+		versions []*versionStats
+	}{
+		{nodeExpired, r.ExpiredVersions},
+		{nodeFrozen, r.FrozenVersions},
+	} {
+		for _, v := range excluded.versions {
+			_ = cw.Write([]string{
+				height,
+				runtimeID,
+				r.Kind,
+				string(excluded.state),
+				v.Version,
+				strconv.Itoa(v.Nodes),
+				"",
+				"",
+				strconv.FormatBool(v.Version == r.TargetVersion),
+				"",
+				"",
+			})
+		}
+	}
+	for _, e := range r.Entities {
+		var escrow, share string
+		if r.Stake != nil && e.Escrow != nil {
+			escrow = e.Escrow.String()
+			share = strconv.FormatFloat(quantityShare(e.Escrow, &r.Stake.TotalEscrow), 'f', -1, 64)
+		}
+		for _, version := range e.Versions {
+			_ = cw.Write([]string{
+				height,
+				runtimeID,
+				r.Kind,
+				"entity",
+				version,
+				"",
+				e.Address,
+				e.Name,
+				strconv.FormatBool(version == r.TargetVersion),
+				escrow,
+				share,
+			})
+		}
+	}
+	if bd := r.Breakdown; bd != nil {
+		// The role, TEE hardware, or enclave identity goes in the name
+		// column.
+		for _, v := range bd.Versions {
+			for _, record := range []struct {
+				kind   string
+				counts map[string]int
+			}{
+				{"role", v.Roles},
+				{"tee", v.TEE},
+			} {
+				for _, name := range sortedKeys(record.counts) {
+					_ = cw.Write([]string{
+						height,
+						runtimeID,
+						r.Kind,
+						record.kind,
+						v.Version,
+						strconv.Itoa(record.counts[name]),
+						"",
+						name,
+						strconv.FormatBool(v.Version == r.TargetVersion),
+						"",
+						"",
+					})
+				}
+			}
+			for _, e := range v.Enclaves {
+				_ = cw.Write([]string{
+					height,
+					runtimeID,
+					r.Kind,
+					"enclave" + formatExpected(e.Expected, "", "_unexpected"),
+					v.Version,
+					strconv.Itoa(e.Nodes),
+					"",
+					e.MrEnclave + "/" + e.MrSigner,
+					strconv.FormatBool(v.Version == r.TargetVersion),
+					"",
+					"",
+				})
+			}
+		}
+	}
+}
+
+func writeReportMarkdown(w io.Writer, r *runtimeReport) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Runtime `%s` versions at height %d\n\n", r.RuntimeID, r.Height)
+	fmt.Fprintf(&b, "Kind: %s\n\n", r.Kind)
+	if r.Name != "" {
+		fmt.Fprintf(&b, "Name: %s\n\n", markdownEscape(r.Name))
+	}
+	owner := "`" + r.Owner + "`"
+	if r.OwnerName != "" {
+		owner += " " + markdownEscape(r.OwnerName)
+	}
+	fmt.Fprintf(&b, "Owner: %s\n\n", owner)
+
+	fmt.Fprintf(&b, "Total nodes: %d\n\n", r.TotalNodes)
+	fmt.Fprintln(&b, "| Version | Nodes |")
+	fmt.Fprintln(&b, "|---|---:|")
+	for _, v := range r.Versions {
+		fmt.Fprintf(&b, "| %s | %d |\n", v.Version, v.Nodes)
+	}
+
+	if len(r.ExpiredVersions) > 0 || len(r.FrozenVersions) > 0 {
+		fmt.Fprint(&b, "\nExcluded nodes:\n\n")
+		fmt.Fprintln(&b, "| State | Version | Nodes |")
+		fmt.Fprintln(&b, "|---|---|---:|")
+		for _, v := range r.ExpiredVersions {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", nodeExpired, v.Version, v.Nodes)
+		}
+		for _, v := range r.FrozenVersions {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", nodeFrozen, v.Version, v.Nodes)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nTotal entities running %s: %d\n\n", r.TargetVersion, len(r.TargetEntities))
+	fmt.Fprintln(&b, "| Entity | Name | Versions | Target |")
+	fmt.Fprintln(&b, "|---|---|---|:---:|")
+	for _, e := range r.Entities {
+		target := ""
+		if e.isOnVersion(r.TargetVersion) {
+			target = "✓"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n",
+			e.Address,
+			markdownEscape(e.Name),
+			strings.Join(e.Versions, ", "),
+			target,
+		)
+	}
+
+	if s := r.Stake; s != nil {
+		fmt.Fprintf(&b, "\nActive escrow, split over each entity's nodes (total: %s):\n\n", s.TotalEscrow)
+		fmt.Fprintln(&b, "| Version | Escrow | Share |")
+		fmt.Fprintln(&b, "|---|---:|---:|")
+		for _, v := range s.Versions {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", v.Version, v.Escrow, formatShare(v.Share))
+		}
+		fmt.Fprintf(&b, "\nUpgraded to %s: %s (%s) of active escrow, %d (%s) of validator voting power\n",
+			r.TargetVersion,
+			s.UpgradedEscrow,
+			formatShare(s.UpgradedShare),
+			s.UpgradedVotingPower,
+			formatShare(s.UpgradedVotingShare),
+		)
+	}
+
+	if bd := r.Breakdown; bd != nil {
+		fmt.Fprintf(&b, "\nNode breakdown (registered version: %s):\n\n", bd.RegisteredVersion)
+		fmt.Fprintln(&b, "| Version | Roles | TEE |")
+		fmt.Fprintln(&b, "|---|---|---|")
+		for _, v := range bd.Versions {
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", v.Version, formatCounts(v.Roles), formatCounts(v.TEE))
+		}
+
+		var hasEnclaves bool
+		for _, v := range bd.Versions {
+			hasEnclaves = hasEnclaves || len(v.Enclaves) > 0
+		}
+		if hasEnclaves {
+			fmt.Fprint(&b, "\nSGX enclaves:\n\n")
+			fmt.Fprintln(&b, "| Version | MRENCLAVE | MRSIGNER | Nodes | Expected |")
+			fmt.Fprintln(&b, "|---|---|---|---:|:---:|")
+			for _, v := range bd.Versions {
+				for _, e := range v.Enclaves {
+					fmt.Fprintf(&b, "| %s | `%s` | `%s` | %d | %s |\n",
+						v.Version,
+						e.MrEnclave,
+						e.MrSigner,
+						e.Nodes,
+						formatExpected(e.Expected, "✓", "✗"),
+					)
+				}
+			}
+		}
+		if len(bd.UnexpectedEnclaves) > 0 {
+			fmt.Fprintf(&b, "\nNodes running %s in an unexpected enclave:\n\n", bd.RegisteredVersion)
+			fmt.Fprintln(&b, "| Node | Entity | MRENCLAVE | MRSIGNER |")
+			fmt.Fprintln(&b, "|---|---|---|---|")
+			for _, n := range bd.UnexpectedEnclaves {
+				fmt.Fprintf(&b, "| `%s` | `%s` | `%s` | `%s` |\n", n.ID, n.Entity, n.MrEnclave, n.MrSigner)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func countNodes(versions []*versionStats) int {
+	var n int
+	for _, v := range versions {
+		n += v.Nodes
+	}
+	return n
+}
+
+// formatCounts formats the counts as "key=count" pairs, ordered by key.
+func formatCounts(counts map[string]int) string {
+	keys := sortedKeys(counts)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%d", k, counts[k]))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatExpected returns ifExpected or ifUnexpected, or "" if whether the
+// enclave is expected is unknown.
+func formatExpected(expected *bool, ifExpected, ifUnexpected string) string {
+	switch {
+	case expected == nil:
+		return ""
+	case *expected:
+		return ifExpected
+	default:
+		return ifUnexpected
+	}
+}
+
+func formatShare(share float64) string {
+	return fmt.Sprintf("%.2f%%", share*100)
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer(
+		"|", "\\|",
+		"\n", " ",
+		"\r", "",
+	).Replace(s)
+}
+
+// writeHistory writes the runtime version adoption history.
+func writeHistory(w io.Writer, format string, h *historyReport) error {
+	switch format {
+	case formatText:
+		return writeHistoryTable(w, h, false)
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(h)
+	case formatCSV:
+		return writeHistoryCSV(w, h)
+	case formatYAML:
+		b, err := yaml.Marshal(h)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case formatMarkdown:
+		return writeHistoryTable(w, h, true)
+	default:
+		return validateFormat(format)
+	}
+}
+
+// writeHistoryTable writes the history as a table with a row per sample,
+// and a `<nodes>/<entities>` column per version.
+func writeHistoryTable(w io.Writer, h *historyReport, isMarkdown bool) error {
+	header := append([]string{"Height", "Epoch", "Time", "Nodes"}, h.Versions...)
+	rows := [][]string{header}
+	for _, s := range h.Samples {
+		row := []string{
+			strconv.FormatInt(s.Height, 10),
+			strconv.FormatUint(uint64(s.Epoch), 10),
+			s.Time.UTC().Format(time.RFC3339),
+			strconv.Itoa(s.TotalNodes),
+		}
+		for _, version := range h.Versions {
+			var nodes, entities int
+			if v := s.lookup(version); v != nil {
+				nodes, entities = v.Nodes, v.Entities
+			}
+			row = append(row, fmt.Sprintf("%d/%d", nodes, entities))
+		}
+		rows = append(rows, row)
+	}
+
+	if isMarkdown {
+		var b strings.Builder
+		fmt.Fprintf(&b, "# Runtime `%s` version adoption (nodes/entities)\n\n", h.RuntimeID)
+		for i, row := range rows {
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(row, " | "))
+			if i == 0 {
+				fmt.Fprintf(&b, "|%s\n", strings.Repeat("---:|", len(row)))
+			}
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	fmt.Fprintf(w, "Runtime version adoption (nodes/entities) for runtime: %s\n\n", h.RuntimeID)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// writeHistoryCSV writes the history with a row per version per sample.
+func writeHistoryCSV(w io.Writer, h *historyReport) error {
+	cw := csv.NewWriter(w)
+
+	_ = cw.Write([]string{"height", "epoch", "time", "total_nodes", "version", "nodes", "entities"})
+	for _, s := range h.Samples {
+		for _, version := range h.Versions {
+			var nodes, entities int
+			if v := s.lookup(version); v != nil {
+				nodes, entities = v.Nodes, v.Entities
+			}
+			_ = cw.Write([]string{
+				strconv.FormatInt(s.Height, 10),
+				strconv.FormatUint(uint64(s.Epoch), 10),
+				s.Time.UTC().Format(time.RFC3339),
+				strconv.Itoa(s.TotalNodes),
+				version,
+				strconv.Itoa(nodes),
+				strconv.Itoa(entities),
+			})
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+const chartWidth = 60
+
+var chartSymbols = []byte("#=+*o%@x~-")
+
+// writeHistoryChart writes a chart with a bar per sample, showing the
+// share of nodes running each version.
+func writeHistoryChart(w io.Writer, h *historyReport) error {
+	var b strings.Builder
+
+	heightWidth := 0
+	for _, s := range h.Samples {
+		if l := len(strconv.FormatInt(s.Height, 10)); l > heightWidth {
+			heightWidth = l
+		}
+	}
+
+	fmt.Fprintln(&b)
+	for _, s := range h.Samples {
+		// Apportion the bar width by the largest remainder method, so
+		// that the bar of each sample with nodes is exactly full.
+		widths := make([]int, len(h.Versions))
+		remainders := make([]int, len(h.Versions))
+		used := 0
+		for i, version := range h.Versions {
+			if v := s.lookup(version); v != nil {
+				widths[i] = v.Nodes * chartWidth / s.TotalNodes
+				remainders[i] = v.Nodes * chartWidth % s.TotalNodes
+				used += widths[i]
+			}
+		}
+		for s.TotalNodes > 0 && used < chartWidth {
+			best := 0
+			for i := range remainders {
+				if remainders[i] > remainders[best] {
+					best = i
+				}
+			}
+			widths[best]++
+			remainders[best] = -1
+			used++
+		}
+
+		fmt.Fprintf(&b, "%*d |", heightWidth, s.Height)
+		for i, width := range widths {
+			b.WriteString(strings.Repeat(string(chartSymbols[i%len(chartSymbols)]), width))
+		}
+		fmt.Fprintf(&b, "%s| %d\n", strings.Repeat(" ", chartWidth-used), s.TotalNodes)
+	}
+
+	fmt.Fprintln(&b)
+	for i, version := range h.Versions {
+		fmt.Fprintf(&b, "%c %s\n", chartSymbols[i%len(chartSymbols)], version)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// laggardsReport is the laggard report on one or more runtimes.
+type laggardsReport struct {
+	RuntimeID     common.Namespace `json:"runtime_id" yaml:"runtime_id"`
+	Kind          string           `json:"kind" yaml:"kind"`
+	Name          string           `json:"name,omitempty" yaml:"name,omitempty"`
+	TargetVersion string           `json:"target_version" yaml:"target_version"`
+	Laggards      []*laggardStats  `json:"laggards" yaml:"laggards"`
+}
+
+// writeLaggards writes the laggard report of each of the runtime reports.
+func writeLaggards(w io.Writer, format string, height int64, reports []*runtimeReport) error {
+	switch format {
+	case formatText:
+		for i, r := range reports {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			writeLaggardsText(w, r, len(reports) > 1)
+		}
+		return nil
+	case formatJSON, formatYAML:
+		v := struct {
+			Height   int64             `json:"height" yaml:"height"`
+			Runtimes []*laggardsReport `json:"runtimes" yaml:"runtimes"`
+		}{
+			Height: height,
+		}
+		for _, r := range reports {
+			v.Runtimes = append(v.Runtimes, &laggardsReport{
+				RuntimeID:     r.RuntimeID,
+				Kind:          r.Kind,
+				Name:          r.Name,
+				TargetVersion: r.TargetVersion,
+				Laggards:      r.Laggards,
+			})
+		}
+		if format == formatYAML {
+			b, err := yaml.Marshal(v)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatCSV:
+		return writeLaggardsCSV(w, reports)
+	case formatMarkdown:
+		for i, r := range reports {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			writeLaggardsMarkdown(w, r)
+		}
+		return nil
+	default:
+		return validateFormat(format)
+	}
+}
+
+func writeLaggardsText(w io.Writer, r *runtimeReport, showRuntime bool) {
+	fmt.Fprintf(w, "Runtime version laggards for height: %d\n", r.Height)
+	if showRuntime {
+		fmt.Fprintf(w, "Runtime: %s\n", r.runtimeDisplayName())
+	}
+
+	fmt.Fprintf(w, "\nTotal entities behind %s: %d\n", r.TargetVersion, len(r.Laggards))
+	for _, l := range r.Laggards {
+		name := l.Name
+		if name == "" {
+			name = noEntityName
+		}
+		fmt.Fprintf(w, "%s %s (%d upgraded nodes)\n", l.Address, name, l.UpgradedNodes)
+		contact := l.contact()
+		if contact == "" {
+			contact = "<no contact information>"
+		}
+		fmt.Fprintf(w, "  %s\n", contact)
+		for _, nv := range l.Nodes {
+			fmt.Fprintf(w, "  node %s: %s\n", nv.ID, strings.Join(nv.Versions, ", "))
+		}
+	}
+}
+
+// writeLaggardsCSV writes the laggards with a row per old node, for
+// mail merges.
+func writeLaggardsCSV(w io.Writer, reports []*runtimeReport) error {
+	cw := csv.NewWriter(w)
+
+	_ = cw.Write([]string{
+		"height", "runtime", "target", "address", "name",
+		"email", "twitter", "keybase", "url",
+		"node", "versions", "upgraded_nodes",
+	})
+	for _, r := range reports {
+		for _, l := range r.Laggards {
+			for _, nv := range l.Nodes {
+				_ = cw.Write([]string{
+					strconv.FormatInt(r.Height, 10),
+					r.RuntimeID.String(),
+					r.TargetVersion,
+					l.Address,
+					l.Name,
+					l.Email,
+					l.Twitter,
+					l.Keybase,
+					l.URL,
+					nv.ID.String(),
+					strings.Join(nv.Versions, " "),
+					strconv.Itoa(l.UpgradedNodes),
+				})
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeLaggardsMarkdown(w io.Writer, r *runtimeReport) {
+	fmt.Fprintf(w, "# Runtime `%s` entities behind %s at height %d\n\n", r.RuntimeID, r.TargetVersion, r.Height)
+	fmt.Fprintln(w, "| Entity | Name | Contact | Old nodes | Upgraded nodes |")
+	fmt.Fprintln(w, "|---|---|---|---|---:|")
+	for _, l := range r.Laggards {
+		var nodes []string
+		for _, nv := range l.Nodes {
+			nodes = append(nodes, fmt.Sprintf("`%s` (%s)", nv.ID, strings.Join(nv.Versions, ", ")))
+		}
+		fmt.Fprintf(w, "| `%s` | %s | %s | %s | %d |\n",
+			l.Address,
+			markdownEscape(l.Name),
+			markdownEscape(l.contact()),
+			strings.Join(nodes, "<br>"),
+			l.UpgradedNodes,
+		)
+	}
+}
+
+// writeReadiness writes the readiness reports.
+func writeReadiness(w io.Writer, format string, rrs *readinessReports) error {
+	switch format {
+	case formatText, formatMarkdown:
+		var b strings.Builder
+		isMarkdown := format == formatMarkdown
+		if isMarkdown {
+			fmt.Fprintf(&b, "# Runtime `%s` readiness at height %d\n\n", rrs.RuntimeID, rrs.Height)
+			fmt.Fprintf(&b, "Epoch: %d\n\nRegistered version: %s\n", rrs.Epoch, rrs.RegisteredVersion)
+		} else {
+			fmt.Fprintf(&b, "Runtime readiness at height: %d (epoch %d)\n", rrs.Height, rrs.Epoch)
+			fmt.Fprintf(&b, "Registered version: %s\n", rrs.RegisteredVersion)
+		}
+		for _, rr := range rrs.Deployments {
+			writeReadinessDeployment(&b, rr, isMarkdown)
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rrs)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"height", "runtime", "version", "address", "name", "nodes", "ready_nodes", "ready"})
+		for _, rr := range rrs.Deployments {
+			for _, er := range rr.Entities {
+				_ = cw.Write([]string{
+					strconv.FormatInt(rr.Height, 10),
+					rr.RuntimeID.String(),
+					rr.Version,
+					er.Address,
+					er.Name,
+					strconv.Itoa(er.Nodes),
+					strconv.Itoa(er.ReadyNodes),
+					strconv.FormatBool(er.IsReady),
+				})
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case formatYAML:
+		b, err := yaml.Marshal(rrs)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		return validateFormat(format)
+	}
+}
+
+func writeReadinessDeployment(b *strings.Builder, rr *readinessReport, isMarkdown bool) {
+	if isMarkdown {
+		fmt.Fprintf(b, "\n## Deployment %s\n", rr.Version)
+	} else {
+		fmt.Fprintf(b, "\nDeployment: %s\n", rr.Version)
+	}
+	if rr.ValidFrom != nil {
+		fmt.Fprintf(b, "\nValid from epoch %d (%d epochs left)\n", *rr.ValidFrom, *rr.EpochsLeft)
+	}
+	fmt.Fprintf(b, "\nReady nodes: %d/%d (%s, threshold %s)\n", rr.ReadyNodes, rr.TotalNodes, formatShare(rr.NodeReadiness), formatShare(rr.Threshold))
+	fmt.Fprintf(b, "Ready entities: %d/%d (%s)\n", rr.ReadyEntities, rr.TotalEntities, formatShare(rr.EntityReadiness))
+
+	if isMarkdown {
+		fmt.Fprintln(b, "\n| Entity | Name | Ready nodes | Ready |")
+		fmt.Fprintln(b, "|---|---|---:|:---:|")
+	} else {
+		fmt.Fprintf(b, "\nEntities not ready:\n")
+	}
+	for _, er := range rr.Entities {
+		switch {
+		case isMarkdown:
+			ready := ""
+			if er.IsReady {
+				ready = "✓"
+			}
+			fmt.Fprintf(b, "| `%s` | %s | %d/%d | %s |\n", er.Address, markdownEscape(er.Name), er.ReadyNodes, er.Nodes, ready)
+		case !er.IsReady:
+			name := er.Name
+			if name == "" {
+				name = noEntityName
+			}
+			fmt.Fprintf(b, "%s %s (%d/%d nodes)\n", er.Address, name, er.ReadyNodes, er.Nodes)
+		}
+	}
+}
+
+// writeSoftware writes the software version report.
+func writeSoftware(w io.Writer, format string, r *softwareReport) error {
+	switch format {
+	case formatText, formatMarkdown:
+		var b strings.Builder
+		isMarkdown := format == formatMarkdown
+		nodes := "nodes"
+		if r.ValidatorsOnly {
+			nodes = "validators"
+		}
+		if isMarkdown {
+			fmt.Fprintf(&b, "# oasis-core software versions at height %d\n\n", r.Height)
+			fmt.Fprintf(&b, "Total %s: %d (%d unknown)\n", nodes, r.TotalNodes, r.UnknownNodes)
+			fmt.Fprintln(&b, "\n| Version | Nodes | Entities |")
+			fmt.Fprintln(&b, "|---|---:|---:|")
+			for _, v := range r.Versions {
+				fmt.Fprintf(&b, "| %s | %d | %d |\n", v.Version, v.Nodes, v.Entities)
+			}
+			fmt.Fprintln(&b, "\n| Entity | Name | Versions | Nodes |")
+			fmt.Fprintln(&b, "|---|---|---|---:|")
+			for _, e := range r.Entities {
+				fmt.Fprintf(&b, "| `%s` | %s | %s | %d |\n", e.Address, markdownEscape(e.Name), strings.Join(e.Versions, ", "), len(e.Nodes))
+			}
+		} else {
+			fmt.Fprintf(&b, "oasis-core software version stats for height: %d\n", r.Height)
+			fmt.Fprintf(&b, "\nTotal %s: %d (%d unknown)\n", nodes, r.TotalNodes, r.UnknownNodes)
+			for _, v := range r.Versions {
+				fmt.Fprintf(&b, "%s: %d (%d entities)\n", v.Version, v.Nodes, v.Entities)
+			}
+			fmt.Fprintf(&b, "\nEntities: %d\n", len(r.Entities))
+			for _, e := range r.Entities {
+				fmt.Fprintln(&b, e.displayName())
+				for _, n := range e.Nodes {
+					fmt.Fprintf(&b, "  %s: %s\n", n.ID, n.Version)
+				}
+			}
+		}
+		_, err := io.WriteString(w, b.String())
+		return err
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		_ = cw.Write([]string{"height", "address", "name", "node", "version"})
+		for _, e := range r.Entities {
+			for _, n := range e.Nodes {
+				_ = cw.Write([]string{
+					strconv.FormatInt(r.Height, 10),
+					e.Address,
+					e.Name,
+					n.ID.String(),
+					n.Version,
+				})
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case formatYAML:
+		b, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	default:
+		return validateFormat(format)
+	}
+}
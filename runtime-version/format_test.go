@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+)
+
+func testFormatReports(t *testing.T) []*runtimeReport {
+	nodes := []*node.Node{
+		testNode(1, 0, "1.0.0"),
+		testNode(1, 1, "2.0.0"),
+		testNode(2, 0, "2.0.0"),
+	}
+	return []*runtimeReport{testReport(t, nodes, nil, "2.0.0")}
+}
+
+// checkFormat checks that the output is well formed, and contains the
+// expected strings.
+func checkFormat(t *testing.T, format string, out []byte, expected ...string) {
+	switch format {
+	case formatJSON:
+		var v interface{}
+		if err := json.Unmarshal(out, &v); err != nil {
+			t.Fatalf("malformed JSON: %v", err)
+		}
+	case formatYAML:
+		var v interface{}
+		if err := yaml.Unmarshal(out, &v); err != nil {
+			t.Fatalf("malformed YAML: %v", err)
+		}
+	case formatCSV:
+		records, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+		if err != nil {
+			t.Fatalf("malformed CSV: %v", err)
+		}
+		if len(records) < 2 {
+			t.Fatalf("CSV: expected a header and rows, got %d records", len(records))
+		}
+	}
+	for _, s := range expected {
+		if !bytes.Contains(out, []byte(s)) {
+			t.Fatalf("output does not contain %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestWriteReports(t *testing.T) {
+	reports := testFormatReports(t)
+	for _, v := range []struct {
+		format   string
+		expected []string
+	}{
+		{formatText, []string{"Total nodes: 3", "1.0.0: 1", "2.0.0: 2", "Total entities running 2.0.0: 2"}},
+		{formatJSON, []string{`"total_nodes": 3`, `"target_version": "2.0.0"`}},
+		{formatYAML, []string{"total_nodes: 3", "target_version: 2.0.0"}},
+		{formatCSV, []string{"height,runtime,kind,record,version,nodes", testAddress(1)}},
+		{formatMarkdown, []string{"Total nodes: 3", "| 1.0.0 | 1 |", "| 2.0.0 | 2 |"}},
+	} {
+		t.Run(v.format, func(t *testing.T) {
+			for _, all := range []bool{false, true} {
+				var buf bytes.Buffer
+				if err := writeReports(&buf, v.format, testHeight, reports, all); err != nil {
+					t.Fatalf("writeReports: %v", err)
+				}
+				checkFormat(t, v.format, buf.Bytes(), v.expected...)
+				if all && (v.format == formatJSON || v.format == formatYAML) && !strings.Contains(buf.String(), "runtimes") {
+					t.Fatalf("writeReports: expected a list of runtimes:\n%s", buf.String())
+				}
+			}
+		})
+	}
+
+	if err := writeReports(&bytes.Buffer{}, "xml", testHeight, reports, false); err == nil {
+		t.Fatalf("writeReports: failed to reject unsupported format")
+	}
+}
+
+func TestWriteLaggards(t *testing.T) {
+	reports := testFormatReports(t)
+	laggard := testNodeID(1, 0).String()
+	upgraded := testNodeID(1, 1).String()
+	for _, v := range []struct {
+		format   string
+		expected []string
+	}{
+		{formatText, []string{"Total entities behind 2.0.0: 1", testAddress(1), "node " + laggard + ": 1.0.0"}},
+		{formatJSON, []string{`"target_version": "2.0.0"`, testAddress(1), laggard}},
+		{formatYAML, []string{"target_version: 2.0.0", testAddress(1), laggard}},
+		{formatCSV, []string{"height,runtime,target,address", testAddress(1) + ",", laggard + ",1.0.0,1"}},
+		{formatMarkdown, []string{"entities behind 2.0.0", "`" + testAddress(1) + "`", "`" + laggard + "` (1.0.0)"}},
+	} {
+		t.Run(v.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeLaggards(&buf, v.format, testHeight, reports); err != nil {
+				t.Fatalf("writeLaggards: %v", err)
+			}
+			checkFormat(t, v.format, buf.Bytes(), v.expected...)
+
+			// Only the nodes that are behind are listed.
+			for _, s := range []string{upgraded, testAddress(2)} {
+				if strings.Contains(buf.String(), s) {
+					t.Fatalf("writeLaggards: output contains %s:\n%s", s, buf.String())
+				}
+			}
+		})
+	}
+
+	if err := writeLaggards(&bytes.Buffer{}, "xml", testHeight, reports); err == nil {
+		t.Fatalf("writeLaggards: failed to reject unsupported format")
+	}
+}
@@ -9,4 +9,5 @@ require (
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.10.1
 	google.golang.org/grpc v1.44.0
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	consensusAPI "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+)
+
+const (
+	// CfgHistoryFrom configures the first sampled height (or epoch).
+	cfgHistoryFrom = "from"
+
+	// CfgHistoryTo configures the last sampled height (or epoch).
+	cfgHistoryTo = "to"
+
+	// CfgHistoryInterval configures the number of heights (or epochs)
+	// between samples.
+	cfgHistoryInterval = "interval"
+
+	// CfgHistorySamples configures the number of samples, if the
+	// interval is not configured.
+	cfgHistorySamples = "samples"
+
+	// CfgHistoryEpochs configures the range and interval to be in epochs
+	// instead of heights.
+	cfgHistoryEpochs = "epochs"
+
+	// CfgHistoryChart configures drawing an ASCII chart.
+	cfgHistoryChart = "chart"
+
+	defaultHistorySamples = 20
+)
+
+var (
+	historyCmdFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+	historyCmd = &cobra.Command{
+		Use:   "history <runtime-id>",
+		Short: "query runtime version adoption over a height or epoch range",
+		Run:   doHistory,
+	}
+)
+
+// historyReport is the runtime version adoption history.
+type historyReport struct {
+	RuntimeID common.Namespace `json:"runtime_id" yaml:"runtime_id"`
+	Versions  []string         `json:"versions" yaml:"versions"`
+	Samples   []*historySample `json:"samples" yaml:"samples"`
+}
+
+// historySample is the runtime version adoption at a single height.
+type historySample struct {
+	Height     int64             `json:"height" yaml:"height"`
+	Epoch      beacon.EpochTime  `json:"epoch" yaml:"epoch"`
+	Time       time.Time         `json:"time" yaml:"time"`
+	TotalNodes int               `json:"total_nodes" yaml:"total_nodes"`
+	Versions   []*historyVersion `json:"versions" yaml:"versions"`
+
+	// ExpiredNodes and FrozenNodes are the number of nodes excluded
+	// from the sample.
+	ExpiredNodes int `json:"expired_nodes" yaml:"expired_nodes"`
+	FrozenNodes  int `json:"frozen_nodes" yaml:"frozen_nodes"`
+}
+
+// historyVersion is the number of nodes and entities running a version.
+type historyVersion struct {
+	Version  string `json:"version" yaml:"version"`
+	Nodes    int    `json:"nodes" yaml:"nodes"`
+	Entities int    `json:"entities" yaml:"entities"`
+}
+
+// lookup returns the sample's stats for the version, if any node runs it.
+func (s *historySample) lookup(version string) *historyVersion {
+	for _, v := range s.Versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return nil
+}
+
+// newHistorySample summarizes a runtime report.
+func newHistorySample(r *runtimeReport, epoch beacon.EpochTime, t time.Time) *historySample {
+	s := &historySample{
+		Height:     r.Height,
+		Epoch:      epoch,
+		Time:       t,
+		TotalNodes: r.TotalNodes,
+		Versions:   []*historyVersion{},
+	}
+	for _, en := range r.ExcludedNodes {
+		switch en.State {
+		case nodeExpired:
+			s.ExpiredNodes++
+		case nodeFrozen:
+			s.FrozenNodes++
+		}
+	}
+	for _, v := range r.Versions {
+		hv := &historyVersion{
+			Version: v.Version,
+			Nodes:   v.Nodes,
+		}
+		for _, e := range r.Entities {
+			if e.isOnVersion(v.Version) {
+				hv.Entities++
+			}
+		}
+		s.Versions = append(s.Versions, hv)
+	}
+	return s
+}
+
+// sampleRange returns the evenly spaced points from from to to (both
+// inclusive), interval apart.  If interval is 0, samples points are
+// spread over the range instead (fewer if the range is smaller).
+func sampleRange(from, to, interval int64, samples int) ([]int64, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid range: %d > %d", from, to)
+	}
+	if interval < 0 || samples < 0 {
+		return nil, fmt.Errorf("invalid interval")
+	}
+
+	var points []int64
+	if interval > 0 {
+		for p := from; p < to; p += interval {
+			points = append(points, p)
+		}
+		return append(points, to), nil
+	}
+	if samples < 2 {
+		return []int64{to}, nil
+	}
+
+	span := to - from
+	for i := 0; i < samples; i++ {
+		p := from + int64(i)*span/int64(samples-1)
+		if n := len(points); n > 0 && points[n-1] == p {
+			continue
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func doHistory(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	doInitGenesis()
+
+	format := doGetFormat()
+	useEpochs := viper.GetBool(cfgHistoryEpochs)
+
+	if len(args) != 1 {
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("need exactly one argument (runtimeID)"))
+	}
+	var runtimeID common.Namespace
+	if err := runtimeID.UnmarshalHex(args[0]); err != nil {
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("malformed runtime ID: %s", args[0]))
+	}
+	conn := doConnect(cmd)
+
+	consensus := consensusAPI.NewConsensusClient(conn)
+	reg := registryAPI.NewRegistryClient(conn)
+	bcn := beacon.NewBeaconClient(conn)
+
+	// Resolve the range, and the heights to sample.
+	from, to := viper.GetInt64(cfgHistoryFrom), viper.GetInt64(cfgHistoryTo)
+	if to == consensusAPI.HeightLatest {
+		to = doGetHeight(ctx, consensus, to)
+		if useEpochs {
+			epoch, err := bcn.GetEpoch(ctx, to)
+			if err != nil {
+				cmdCommon.EarlyLogAndExit(err)
+			}
+			to = int64(epoch)
+		}
+	}
+	points, err := sampleRange(from, to, viper.GetInt64(cfgHistoryInterval), viper.GetInt(cfgHistorySamples))
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	heights := points
+	if useEpochs {
+		heights = make([]int64, 0, len(points))
+		for _, epoch := range points {
+			height, err := bcn.GetEpochBlock(ctx, beacon.EpochTime(epoch))
+			if err != nil {
+				cmdCommon.EarlyLogAndExit(fmt.Errorf("failed to query height of epoch %d: %w", epoch, err))
+			}
+			heights = append(heights, height)
+		}
+	}
+
+	// The runtime descriptor at the end of the range is used for every
+	// sample, as only the runtime ID is needed to match nodes.
+	runtimes, err := reg.GetRuntimes(ctx, &registryAPI.GetRuntimesQuery{
+		Height:           heights[len(heights)-1],
+		IncludeSuspended: true,
+	})
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	runtimes = filterRuntimes(runtimes, runtimeID)
+	if len(runtimes) == 0 {
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("no such runtime: %s", runtimeID))
+	}
+	rt := runtimes[0]
+
+	h := &historyReport{
+		RuntimeID: runtimeID,
+	}
+	seenVersions := make(map[version.Version]bool)
+	for _, height := range heights {
+		nodes, err := reg.GetNodes(ctx, height)
+		if err != nil {
+			cmdCommon.EarlyLogAndExit(fmt.Errorf("failed to query nodes at height %d: %w", height, err))
+		}
+		blk, err := consensus.GetBlock(ctx, height)
+		if err != nil {
+			cmdCommon.EarlyLogAndExit(fmt.Errorf("failed to query block at height %d: %w", height, err))
+		}
+		epoch, err := bcn.GetEpoch(ctx, height)
+		if err != nil {
+			cmdCommon.EarlyLogAndExit(fmt.Errorf("failed to query epoch at height %d: %w", height, err))
+		}
+
+		states := doGetNodeStates(ctx, reg, bcn, height, nodes)
+
+		r := buildRuntimeReport(ctx, nil, height, rt, nodes, states, nil)
+		for _, v := range r.Versions {
+			seenVersions[v.version] = true
+		}
+		h.Samples = append(h.Samples, newHistorySample(r, epoch, blk.Time))
+	}
+	versions := make([]version.Version, 0, len(seenVersions))
+	for v := range seenVersions {
+		versions = append(versions, v)
+	}
+	sortVersions(versions)
+	for _, v := range versions {
+		h.Versions = append(h.Versions, v.String())
+	}
+
+	if err = writeHistory(os.Stdout, format, h); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	if viper.GetBool(cfgHistoryChart) {
+		// Keep machine-readable output parsable.
+		var w io.Writer = os.Stdout
+		if format != formatText {
+			w = os.Stderr
+		}
+		if err = writeHistoryChart(w, h); err != nil {
+			cmdCommon.EarlyLogAndExit(err)
+		}
+	}
+}
+
+func init() {
+	historyCmdFlags.Int64(cfgHistoryFrom, 0, "first height (or epoch) to sample")
+	historyCmdFlags.Int64(
+		cfgHistoryTo,
+		consensusAPI.HeightLatest,
+		"last height (or epoch) to sample (default latest)",
+	)
+	historyCmdFlags.Int64(cfgHistoryInterval, 0, "heights (or epochs) between samples (default spread over --samples)")
+	historyCmdFlags.Int(cfgHistorySamples, defaultHistorySamples, "number of samples, if --interval is not set")
+	historyCmdFlags.Bool(cfgHistoryEpochs, false, "range and interval are in epochs instead of heights")
+	historyCmdFlags.Bool(cfgHistoryChart, false, "draw an ASCII chart of the node share of each version")
+	_ = viper.BindPFlags(historyCmdFlags)
+	historyCmd.Flags().AddFlagSet(historyCmdFlags)
+	_ = historyCmd.MarkFlagRequired(cfgHistoryFrom)
+
+	queryCmd.AddCommand(historyCmd)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSampleRange(t *testing.T) {
+	for _, v := range []struct {
+		name     string
+		from     int64
+		to       int64
+		interval int64
+		samples  int
+
+		expected []int64
+	}{
+		{
+			name:     "Samples",
+			from:     0,
+			to:       100,
+			samples:  5,
+			expected: []int64{0, 25, 50, 75, 100},
+		},
+		{
+			name:     "SamplesUneven",
+			from:     0,
+			to:       100,
+			samples:  20,
+			expected: []int64{0, 5, 10, 15, 21, 26, 31, 36, 42, 47, 52, 57, 63, 68, 73, 78, 84, 89, 94, 100},
+		},
+		{
+			name:     "SamplesOffset",
+			from:     1000,
+			to:       1010,
+			samples:  3,
+			expected: []int64{1000, 1005, 1010},
+		},
+		{
+			name:     "SamplesMoreThanRange",
+			from:     10,
+			to:       13,
+			samples:  20,
+			expected: []int64{10, 11, 12, 13},
+		},
+		{
+			name:     "SamplesEmptyRange",
+			from:     7,
+			to:       7,
+			samples:  20,
+			expected: []int64{7},
+		},
+		{
+			name:     "SingleSample",
+			from:     0,
+			to:       100,
+			samples:  1,
+			expected: []int64{100},
+		},
+		{
+			name:     "Interval",
+			from:     0,
+			to:       100,
+			interval: 30,
+			samples:  20,
+			expected: []int64{0, 30, 60, 90, 100},
+		},
+		{
+			name:     "IntervalExact",
+			from:     0,
+			to:       90,
+			interval: 30,
+			expected: []int64{0, 30, 60, 90},
+		},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			points, err := sampleRange(v.from, v.to, v.interval, v.samples)
+			if err != nil {
+				t.Fatalf("sampleRange: %v", err)
+			}
+			if !reflect.DeepEqual(points, v.expected) {
+				t.Fatalf("sampleRange: expected %v, got %v", v.expected, points)
+			}
+			if v.interval == 0 && v.samples > 0 && len(points) > v.samples {
+				t.Fatalf("sampleRange: %d points for %d samples", len(points), v.samples)
+			}
+		})
+	}
+
+	for _, v := range []struct {
+		name     string
+		from     int64
+		to       int64
+		interval int64
+		samples  int
+	}{
+		{"InvertedRange", 10, 0, 0, 20},
+		{"NegativeInterval", 0, 10, -1, 20},
+		{"NegativeSamples", 0, 10, 0, -1},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			if _, err := sampleRange(v.from, v.to, v.interval, v.samples); err == nil {
+				t.Fatalf("sampleRange: failed to reject invalid arguments")
+			}
+		})
+	}
+}
@@ -3,39 +3,75 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 
+	beaconAPI "github.com/oasisprotocol/oasis-core/go/beacon/api"
 	"github.com/oasisprotocol/oasis-core/go/common"
-	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
 	consensusAPI "github.com/oasisprotocol/oasis-core/go/consensus/api"
 	genesisFile "github.com/oasisprotocol/oasis-core/go/genesis/file"
 	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
 	cmdCommonFlags "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/flags"
 	cmdGrpc "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/grpc"
 	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
-	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+	schedulerAPI "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+	stakingAPI "github.com/oasisprotocol/oasis-core/go/staking/api"
 
 	metadataRegistry "github.com/oasisprotocol/metadata-registry-tools"
 )
 
-// CfgHeight configures the consensus height.
-const cfgHeight = "height"
+const (
+	// CfgHeight configures the consensus height.
+	cfgHeight = "height"
+
+	// CfgFormat configures the output format.
+	cfgFormat = "format"
+
+	// CfgAll configures reporting on all runtimes.
+	cfgAll = "all"
+
+	// CfgRuntimeName configures the names of runtimes.
+	cfgRuntimeName = "runtime-name"
+
+	// CfgLaggards configures reporting the entities with nodes that are
+	// behind the target version instead.
+	cfgLaggards = "laggards"
+
+	// CfgIncludeInactive configures including expired and frozen nodes
+	// in the version stats.
+	cfgIncludeInactive = "include-inactive"
+
+	// CfgStake configures querying the stake-weighted adoption.
+	cfgStake = "stake"
+
+	// CfgBreakdown configures the node role and TEE breakdown.
+	cfgBreakdown = "breakdown"
+
+	// CfgTargetVersion configures the version the entities are compared
+	// against.
+	cfgTargetVersion = "target-version"
+)
 
 var (
+	outputFlags   = flag.NewFlagSet("", flag.ContinueOnError)
+	nodeFlags     = flag.NewFlagSet("", flag.ContinueOnError)
+	heightFlags   = flag.NewFlagSet("", flag.ContinueOnError)
 	queryCmdFlags = flag.NewFlagSet("", flag.ContinueOnError)
 
 	queryCmd = &cobra.Command{
-		Use:   "<runtime-id>",
+		Use:   "runtime-version [<runtime-id>]",
 		Short: "query runtime versions",
+		Args:  cobra.ArbitraryArgs,
 		Run:   doQuery,
 	}
 )
-var runtimeID common.Namespace
 
 func doConnect(cmd *cobra.Command) *grpc.ClientConn {
 	if err := cmdCommon.Init(); err != nil {
@@ -50,9 +86,7 @@ func doConnect(cmd *cobra.Command) *grpc.ClientConn {
 	return conn
 }
 
-func doQuery(cmd *cobra.Command, args []string) {
-	ctx := context.Background()
-
+func doInitGenesis() {
 	genesis, err := genesisFile.DefaultFileProvider()
 	if err != nil {
 		cmdCommon.EarlyLogAndExit(err)
@@ -62,20 +96,17 @@ func doQuery(cmd *cobra.Command, args []string) {
 		cmdCommon.EarlyLogAndExit(err)
 	}
 	doc.SetChainContext()
+}
 
-	height := viper.GetInt64(cfgHeight)
-
-	if len(args) != 1 {
-		cmdCommon.EarlyLogAndExit(fmt.Errorf("need exactly one argument (runtimeID)"))
-	}
-	if err = runtimeID.UnmarshalHex(args[0]); err != nil {
-		cmdCommon.EarlyLogAndExit(fmt.Errorf("malformed runtime ID: %s", args[0]))
+func doGetFormat() string {
+	format := viper.GetString(cfgFormat)
+	if err := validateFormat(format); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
 	}
-	conn := doConnect(cmd)
-
-	consensus := consensusAPI.NewConsensusClient(conn)
-	reg := registryAPI.NewRegistryClient(conn)
+	return format
+}
 
+func doGetHeight(ctx context.Context, consensus consensusAPI.ClientBackend, height int64) int64 {
 	// If height is latest height, take height from latest block.
 	if height == consensusAPI.HeightLatest {
 		blk, err := consensus.GetBlock(ctx, consensusAPI.HeightLatest)
@@ -84,72 +115,148 @@ func doQuery(cmd *cobra.Command, args []string) {
 		}
 		height = blk.Height
 	}
+	return height
+}
 
-	// Get nodes
-	nodes, err := reg.GetNodes(ctx, height)
+func doGetNodeStates(
+	ctx context.Context,
+	reg registryAPI.Backend,
+	bcn beaconAPI.Backend,
+	height int64,
+	nodes []*node.Node,
+) nodeStates {
+	if viper.GetBool(cfgIncludeInactive) {
+		return nil
+	}
+
+	epoch, err := bcn.GetEpoch(ctx, height)
 	if err != nil {
 		cmdCommon.EarlyLogAndExit(err)
 	}
+	states, err := fetchNodeStates(ctx, reg, height, epoch, nodes)
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	return states
+}
 
-	entityVersions := make(map[signature.PublicKey]map[string]bool)
-	versionCounts := make(map[string]int)
-	totalNodes := 0
+func doQuery(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
 
-	for _, node := range nodes {
-		for _, runtime := range node.Runtimes {
-			if runtime.ID == runtimeID {
-				versionString := runtime.Version.String()
-				versionCounts[versionString] = versionCounts[versionString] + 1
-				totalNodes += 1
+	doInitGenesis()
 
-				// Store runtime version info for current entity.
-				if _, ok := entityVersions[node.EntityID]; !ok {
-					entityVersions[node.EntityID] = make(map[string]bool)
-				}
-				entityVersions[node.EntityID][versionString] = true
-			}
+	height := viper.GetInt64(cfgHeight)
+	format := doGetFormat()
+	target, err := parseTargetVersion(viper.GetString(cfgTargetVersion))
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	runtimeNames, err := parseRuntimeNames(viper.GetStringSlice(cfgRuntimeName))
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	all := viper.GetBool(cfgAll)
+	var runtimeID common.Namespace
+	switch {
+	case all && len(args) != 0:
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("runtimeID and --%s are mutually exclusive", cfgAll))
+	case all:
+	case len(args) != 1:
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("need exactly one argument (runtimeID)"))
+	default:
+		if err = runtimeID.UnmarshalHex(args[0]); err != nil {
+			cmdCommon.EarlyLogAndExit(fmt.Errorf("malformed runtime ID: %s", args[0]))
 		}
 	}
+	conn := doConnect(cmd)
+
+	consensus := consensusAPI.NewConsensusClient(conn)
+	reg := registryAPI.NewRegistryClient(conn)
 
-	fmt.Printf("Runtime version stats for height: %d\n", height)
+	height = doGetHeight(ctx, consensus, height)
 
-	// Node version stats
-	fmt.Println("\nTotal nodes:", totalNodes)
-	versionKeys := make([]string, 0, len(versionCounts))
-	for key, _ := range versionCounts {
-		versionKeys = append(versionKeys, key)
+	// Get runtimes
+	runtimes, err := reg.GetRuntimes(ctx, &registryAPI.GetRuntimesQuery{
+		Height:           height,
+		IncludeSuspended: true,
+	})
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
 	}
-	sort.Strings(versionKeys)
-	for _, key := range versionKeys {
-		fmt.Printf("%s: %d\n", key, versionCounts[key])
+	if !all {
+		runtimes = filterRuntimes(runtimes, runtimeID)
+		if len(runtimes) == 0 {
+			cmdCommon.EarlyLogAndExit(fmt.Errorf("no such runtime: %s", runtimeID))
+		}
+	}
+	sort.Slice(runtimes, func(i, j int) bool {
+		return runtimes[i].ID.String() < runtimes[j].ID.String()
+	})
+
+	// Get nodes
+	nodes, err := reg.GetNodes(ctx, height)
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
 	}
+	states := doGetNodeStates(ctx, reg, beaconAPI.NewBeaconClient(conn), height, nodes)
 
-	// Entities running latest version
 	gp, err := metadataRegistry.NewGitProvider(metadataRegistry.NewGitConfig())
 	if err != nil {
 		cmdCommon.EarlyLogAndExit(err)
 	}
 
-	latestVersion := versionKeys[len(versionKeys)-1]
-	updatedEntities := make([]string, 0)
-	for entity, versions := range entityVersions {
-		if versions[latestVersion] {
-			name := "<none>"
-			meta, err := gp.GetEntity(ctx, entity)
-			if err == nil {
-				name = meta.Name
+	var stake *stakeInfo
+	if viper.GetBool(cfgStake) {
+		stake, err = fetchStakeInfo(
+			ctx,
+			reg,
+			stakingAPI.NewStakingClient(conn),
+			schedulerAPI.NewSchedulerClient(conn),
+			height,
+			nodes,
+		)
+		if err != nil {
+			cmdCommon.EarlyLogAndExit(err)
+		}
+	}
+
+	var reports []*runtimeReport
+	for _, rt := range runtimes {
+		r := buildRuntimeReport(ctx, gp, height, rt, nodes, states, target)
+		if name, ok := runtimeNames[rt.ID]; ok {
+			r.Name = name
+		}
+		if stake != nil {
+			r.applyStake(stake)
+		}
+		if viper.GetBool(cfgBreakdown) {
+			if err = r.applyBreakdown(rt, nodes, states); err != nil {
+				cmdCommon.EarlyLogAndExit(err)
 			}
-			addr := staking.NewAddress(entity)
-			updatedEntities = append(updatedEntities, addr.String()+" "+name)
 		}
+		reports = append(reports, r)
 	}
-	sort.Strings(updatedEntities)
-	fmt.Printf("\nTotal entities running %s: %d\n", latestVersion, len(updatedEntities))
-	for _, entity := range updatedEntities {
-		fmt.Println(entity)
+	if viper.GetBool(cfgLaggards) {
+		err = writeLaggards(os.Stdout, format, height, reports)
+	} else {
+		err = writeReports(os.Stdout, format, height, reports, all)
+	}
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
 	}
 }
 
+func filterRuntimes(runtimes []*registryAPI.Runtime, id common.Namespace) []*registryAPI.Runtime {
+	for _, rt := range runtimes {
+		if rt.ID == id {
+			return []*registryAPI.Runtime{rt}
+		}
+	}
+	return nil
+}
+
 func main() {
 	if err := queryCmd.Execute(); err != nil {
 		cmdCommon.EarlyLogAndExit(err)
@@ -159,11 +266,43 @@ func main() {
 func init() {
 	queryCmd.PersistentFlags().AddFlagSet(cmdGrpc.ClientFlags)
 	queryCmd.PersistentFlags().AddFlagSet(cmdCommonFlags.GenesisFileFlags)
-	queryCmdFlags.Int64(
+	outputFlags.String(
+		cfgFormat,
+		formatText,
+		fmt.Sprintf("output format (%s)", strings.Join(reportFormats, ", ")),
+	)
+	_ = viper.BindPFlags(outputFlags)
+	queryCmd.PersistentFlags().AddFlagSet(outputFlags)
+	nodeFlags.Bool(cfgIncludeInactive, false, "include expired and frozen nodes in the version stats")
+	_ = viper.BindPFlags(nodeFlags)
+	queryCmd.PersistentFlags().AddFlagSet(nodeFlags)
+
+	heightFlags.Int64(
 		cfgHeight,
 		consensusAPI.HeightLatest,
 		fmt.Sprintf("height at which to query for info (default %d, i.e. latest height)", consensusAPI.HeightLatest),
 	)
+	_ = viper.BindPFlags(heightFlags)
+	queryCmd.Flags().AddFlagSet(heightFlags)
+
+	queryCmdFlags.Bool(cfgAll, false, "report on all registered runtimes")
+	queryCmdFlags.StringSlice(
+		cfgRuntimeName,
+		nil,
+		"runtime name override, as <runtime-id>=<name> (may be repeated, default metadata registry name of the runtime's owner)",
+	)
+	queryCmdFlags.Bool(cfgLaggards, false, "report the entities with any node running only versions older than the target version")
+	queryCmdFlags.Bool(cfgStake, false, "query the stake-weighted adoption")
+	queryCmdFlags.Bool(
+		cfgBreakdown,
+		false,
+		"break down each version by node role and TEE (enclave identities are only checked for the registered version)",
+	)
+	queryCmdFlags.String(
+		cfgTargetVersion,
+		"",
+		"version to compare the entities against (default latest version)",
+	)
 	_ = viper.BindPFlags(queryCmdFlags)
 	queryCmd.Flags().AddFlagSet(queryCmdFlags)
 }
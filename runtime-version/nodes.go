@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+)
+
+// nodeState is whether a node is taking part in the network.
+type nodeState string
+
+const (
+	nodeActive  nodeState = "active"
+	nodeExpired nodeState = "expired"
+	nodeFrozen  nodeState = "frozen"
+)
+
+// nodeStates is the state of each node.  A nil nodeStates considers every
+// node active.
+type nodeStates map[signature.PublicKey]nodeState
+
+func (s nodeStates) get(id signature.PublicKey) nodeState {
+	if state, ok := s[id]; ok {
+		return state
+	}
+	return nodeActive
+}
+
+// fetchNodeStates classifies the nodes as expired (the registration
+// expired before the epoch), frozen, or active.
+func fetchNodeStates(
+	ctx context.Context,
+	reg registryAPI.Backend,
+	height int64,
+	epoch beacon.EpochTime,
+	nodes []*node.Node,
+) (nodeStates, error) {
+	states := make(nodeStates)
+	for _, n := range nodes {
+		if n.IsExpired(uint64(epoch)) {
+			states[n.ID] = nodeExpired
+			continue
+		}
+
+		status, err := reg.GetNodeStatus(ctx, &registryAPI.IDQuery{
+			Height: height,
+			ID:     n.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query status of node %s: %w", n.ID, err)
+		}
+		if status.IsFrozen() {
+			states[n.ID] = nodeFrozen
+			continue
+		}
+		states[n.ID] = nodeActive
+	}
+	return states, nil
+}
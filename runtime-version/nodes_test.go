@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+)
+
+// testRegistry is a registry backend that only implements the node status
+// queries, for the nodes in frozen.
+type testRegistry struct {
+	registryAPI.Backend
+
+	frozen  map[signature.PublicKey]bool
+	queried []signature.PublicKey
+}
+
+func (r *testRegistry) GetNodeStatus(ctx context.Context, query *registryAPI.IDQuery) (*registryAPI.NodeStatus, error) {
+	if query.Height != testHeight {
+		return nil, fmt.Errorf("unexpected height: %d", query.Height)
+	}
+	r.queried = append(r.queried, query.ID)
+
+	var status registryAPI.NodeStatus
+	if r.frozen[query.ID] {
+		status.FreezeEndTime = testEpoch + 10
+	}
+	return &status, nil
+}
+
+func TestFetchNodeStates(t *testing.T) {
+	active := testNode(1, 0, "1.0.0")
+	active.Expiration = testEpoch
+	expired := testNode(1, 1, "1.0.0")
+	expired.Expiration = testEpoch - 1
+	frozen := testNode(2, 0, "1.0.0")
+	frozen.Expiration = testEpoch + 1
+	// Expiry takes precedence over the node status.
+	expiredFrozen := testNode(2, 1, "1.0.0")
+	expiredFrozen.Expiration = testEpoch - 1
+
+	reg := &testRegistry{
+		frozen: map[signature.PublicKey]bool{
+			frozen.ID:        true,
+			expiredFrozen.ID: true,
+		},
+	}
+	nodes := []*node.Node{active, expired, frozen, expiredFrozen}
+	states, err := fetchNodeStates(context.Background(), reg, testHeight, testEpoch, nodes)
+	if err != nil {
+		t.Fatalf("fetchNodeStates: %v", err)
+	}
+
+	expected := nodeStates{
+		active.ID:        nodeActive,
+		expired.ID:       nodeExpired,
+		frozen.ID:        nodeFrozen,
+		expiredFrozen.ID: nodeExpired,
+	}
+	if !reflect.DeepEqual(states, expected) {
+		t.Fatalf("fetchNodeStates: expected %v, got %v", expected, states)
+	}
+	// The status of expired nodes is not queried.
+	if queried := []signature.PublicKey{active.ID, frozen.ID}; !reflect.DeepEqual(reg.queried, queried) {
+		t.Fatalf("fetchNodeStates: expected status queries for %v, got %v", queried, reg.queried)
+	}
+	// Unknown nodes are considered active.
+	if state := states.get(testNodeID(3, 0)); state != nodeActive {
+		t.Fatalf("state of an unknown node: expected %s, got %s", nodeActive, state)
+	}
+
+	// Excluded nodes are not counted towards the runtime stats.
+	r := testReport(t, nodes, states, "")
+	if r.TotalNodes != 1 {
+		t.Fatalf("total nodes: expected 1, got %d", r.TotalNodes)
+	}
+	if n := countNodes(r.ExpiredVersions); n != 2 {
+		t.Fatalf("expired nodes: expected 2, got %d", n)
+	}
+	if n := countNodes(r.FrozenVersions); n != 1 {
+		t.Fatalf("frozen nodes: expected 1, got %d", n)
+	}
+}
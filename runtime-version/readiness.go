@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	consensusAPI "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+
+	metadataRegistry "github.com/oasisprotocol/metadata-registry-tools"
+)
+
+const (
+	// CfgReadinessVersion configures checking a single deployment
+	// version instead.
+	cfgReadinessVersion = "deployment-version"
+
+	// CfgReadinessValidFrom configures the epoch the deployment version
+	// is valid from.
+	cfgReadinessValidFrom = "valid-from"
+
+	// CfgReadinessThreshold configures the minimum share of ready nodes.
+	cfgReadinessThreshold = "threshold"
+
+	defaultReadinessThreshold = 2.0 / 3.0
+)
+
+var (
+	readinessCmdFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+	readinessCmd = &cobra.Command{
+		Use:   "readiness <runtime-id>",
+		Short: "check node readiness for a runtime deployment",
+		Run:   doReadiness,
+	}
+)
+
+// readinessReports is the readiness of the nodes for the deployments of
+// a runtime.
+type readinessReports struct {
+	Height    int64            `json:"height" yaml:"height"`
+	Epoch     beacon.EpochTime `json:"epoch" yaml:"epoch"`
+	RuntimeID common.Namespace `json:"runtime_id" yaml:"runtime_id"`
+
+	// RegisteredVersion is the version in the runtime descriptor.
+	RegisteredVersion string `json:"registered_version" yaml:"registered_version"`
+
+	Deployments []*readinessReport `json:"deployments" yaml:"deployments"`
+}
+
+// notReady returns the deployments below the readiness threshold.
+func (rrs *readinessReports) notReady() []*readinessReport {
+	var notReady []*readinessReport
+	for _, rr := range rrs.Deployments {
+		if !rr.IsReady {
+			notReady = append(notReady, rr)
+		}
+	}
+	return notReady
+}
+
+// readinessReport is the readiness of the nodes for a deployment.
+type readinessReport struct {
+	Height    int64            `json:"height" yaml:"height"`
+	Epoch     beacon.EpochTime `json:"epoch" yaml:"epoch"`
+	RuntimeID common.Namespace `json:"runtime_id" yaml:"runtime_id"`
+
+	// Version is the deployment version, and ValidFrom the epoch the
+	// deployment is valid from, if known.
+	Version    string            `json:"version" yaml:"version"`
+	ValidFrom  *beacon.EpochTime `json:"valid_from,omitempty" yaml:"valid_from,omitempty"`
+	EpochsLeft *int64            `json:"epochs_left,omitempty" yaml:"epochs_left,omitempty"`
+
+	TotalNodes      int     `json:"total_nodes" yaml:"total_nodes"`
+	ReadyNodes      int     `json:"ready_nodes" yaml:"ready_nodes"`
+	TotalEntities   int     `json:"total_entities" yaml:"total_entities"`
+	ReadyEntities   int     `json:"ready_entities" yaml:"ready_entities"`
+	NodeReadiness   float64 `json:"node_readiness" yaml:"node_readiness"`
+	EntityReadiness float64 `json:"entity_readiness" yaml:"entity_readiness"`
+
+	// Threshold is the minimum node readiness.
+	Threshold float64 `json:"threshold" yaml:"threshold"`
+	IsReady   bool    `json:"ready" yaml:"ready"`
+
+	Entities []*entityReadiness `json:"entities" yaml:"entities"`
+}
+
+// entityReadiness is the readiness of an entity's nodes.
+type entityReadiness struct {
+	Address    string `json:"address" yaml:"address"`
+	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
+	Nodes      int    `json:"nodes" yaml:"nodes"`
+	ReadyNodes int    `json:"ready_nodes" yaml:"ready_nodes"`
+	IsReady    bool   `json:"ready" yaml:"ready"`
+}
+
+// pendingDeployments returns the deployment versions to check: the
+// registered version, followed by the newer versions advertised by the
+// nodes in the runtime report.  The runtime descriptors carry no list of
+// scheduled deployments, but the nodes advertise the version of an
+// upcoming deployment as soon as they are upgraded for it.
+func pendingDeployments(r *runtimeReport, registered version.Version) []version.Version {
+	deployments := []version.Version{registered}
+	for _, v := range r.Versions {
+		if v.version.ToU64() > registered.ToU64() {
+			deployments = append(deployments, v.version)
+		}
+	}
+	return deployments
+}
+
+// buildReadinessReport checks which of the nodes in the runtime report
+// are ready for the deployment version, ie: advertise it or a newer
+// version (as with the laggards).  An entity is ready iff all of its
+// nodes are.
+func buildReadinessReport(
+	r *runtimeReport,
+	deployment version.Version,
+	epoch beacon.EpochTime,
+	validFrom *beacon.EpochTime,
+	threshold float64,
+) *readinessReport {
+	rr := &readinessReport{
+		Height:    r.Height,
+		Epoch:     epoch,
+		RuntimeID: r.RuntimeID,
+		Version:   deployment.String(),
+		ValidFrom: validFrom,
+		Threshold: threshold,
+		Entities:  []*entityReadiness{},
+	}
+	if validFrom != nil {
+		epochsLeft := int64(*validFrom) - int64(epoch)
+		rr.EpochsLeft = &epochsLeft
+	}
+
+	for _, e := range r.Entities {
+		er := &entityReadiness{
+			Address: e.Address,
+			Name:    e.Name,
+			Nodes:   len(e.nodes),
+		}
+		for _, nv := range e.nodes {
+			if nv.latest.ToU64() >= deployment.ToU64() {
+				er.ReadyNodes++
+			}
+		}
+		er.IsReady = er.Nodes > 0 && er.ReadyNodes == er.Nodes
+
+		rr.TotalNodes += er.Nodes
+		rr.ReadyNodes += er.ReadyNodes
+		rr.TotalEntities++
+		if er.IsReady {
+			rr.ReadyEntities++
+		}
+		rr.Entities = append(rr.Entities, er)
+	}
+	if rr.TotalNodes > 0 {
+		rr.NodeReadiness = float64(rr.ReadyNodes) / float64(rr.TotalNodes)
+	}
+	if rr.TotalEntities > 0 {
+		rr.EntityReadiness = float64(rr.ReadyEntities) / float64(rr.TotalEntities)
+	}
+	rr.IsReady = rr.TotalNodes > 0 && rr.NodeReadiness >= threshold
+
+	return rr
+}
+
+func doReadiness(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	doInitGenesis()
+
+	format := doGetFormat()
+	threshold := viper.GetFloat64(cfgReadinessThreshold)
+	if threshold < 0 || threshold > 1 {
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("threshold must be between 0 and 1"))
+	}
+	var deployment *version.Version
+	if s := viper.GetString(cfgReadinessVersion); s != "" {
+		v, err := version.FromString(s)
+		if err != nil {
+			cmdCommon.EarlyLogAndExit(fmt.Errorf("malformed deployment version: %s", s))
+		}
+		deployment = &v
+	}
+	var validFrom *beacon.EpochTime
+	if cmd.Flags().Changed(cfgReadinessValidFrom) {
+		if deployment == nil {
+			cmdCommon.EarlyLogAndExit(fmt.Errorf("--%s requires --%s", cfgReadinessValidFrom, cfgReadinessVersion))
+		}
+		epoch := beacon.EpochTime(viper.GetUint64(cfgReadinessValidFrom))
+		validFrom = &epoch
+	}
+
+	if len(args) != 1 {
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("need exactly one argument (runtimeID)"))
+	}
+	var runtimeID common.Namespace
+	if err := runtimeID.UnmarshalHex(args[0]); err != nil {
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("malformed runtime ID: %s", args[0]))
+	}
+	conn := doConnect(cmd)
+
+	consensus := consensusAPI.NewConsensusClient(conn)
+	reg := registryAPI.NewRegistryClient(conn)
+	bcn := beacon.NewBeaconClient(conn)
+
+	height := doGetHeight(ctx, consensus, viper.GetInt64(cfgHeight))
+	epoch, err := bcn.GetEpoch(ctx, height)
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	runtimes, err := reg.GetRuntimes(ctx, &registryAPI.GetRuntimesQuery{
+		Height:           height,
+		IncludeSuspended: true,
+	})
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	runtimes = filterRuntimes(runtimes, runtimeID)
+	if len(runtimes) == 0 {
+		cmdCommon.EarlyLogAndExit(fmt.Errorf("no such runtime: %s", runtimeID))
+	}
+	rt := runtimes[0]
+
+	nodes, err := reg.GetNodes(ctx, height)
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	gp, err := metadataRegistry.NewGitProvider(metadataRegistry.NewGitConfig())
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	states := doGetNodeStates(ctx, reg, bcn, height, nodes)
+
+	r := buildRuntimeReport(ctx, gp, height, rt, nodes, states, nil)
+	deployments := pendingDeployments(r, rt.Version.Version)
+	if deployment != nil {
+		deployments = []version.Version{*deployment}
+	}
+
+	rrs := &readinessReports{
+		Height:            height,
+		Epoch:             epoch,
+		RuntimeID:         rt.ID,
+		RegisteredVersion: rt.Version.Version.String(),
+	}
+	for _, v := range deployments {
+		rrs.Deployments = append(rrs.Deployments, buildReadinessReport(r, v, epoch, validFrom, threshold))
+	}
+	if err = writeReadiness(os.Stdout, format, rrs); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	if notReady := rrs.notReady(); len(notReady) > 0 {
+		var below []string
+		for _, rr := range notReady {
+			below = append(below, fmt.Sprintf("%s (%s)", rr.Version, formatShare(rr.NodeReadiness)))
+		}
+		cmdCommon.EarlyLogAndExit(fmt.Errorf(
+			"node readiness is below the threshold of %s for: %s",
+			formatShare(threshold),
+			strings.Join(below, ", "),
+		))
+	}
+}
+
+func init() {
+	readinessCmdFlags.String(
+		cfgReadinessVersion,
+		"",
+		"only check this deployment version (default the registered version and the newer versions advertised by the nodes)",
+	)
+	readinessCmdFlags.Uint64(cfgReadinessValidFrom, 0, "epoch the --deployment-version is valid from")
+	readinessCmdFlags.Float64(cfgReadinessThreshold, defaultReadinessThreshold, "minimum share of ready nodes (0-1)")
+	_ = viper.BindPFlags(readinessCmdFlags)
+	readinessCmd.Flags().AddFlagSet(readinessCmdFlags)
+	readinessCmd.Flags().AddFlagSet(heightFlags)
+
+	queryCmd.AddCommand(readinessCmd)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+)
+
+const testEpoch = 100
+
+func TestReadinessThreshold(t *testing.T) {
+	nodes := []*node.Node{
+		// Ready.
+		testNode(1, 0, "2.0.0"),
+		testNode(1, 1, "1.0.0", "2.0.0"),
+		// Partially ready.
+		testNode(2, 0, "2.0.0"),
+		testNode(2, 1, "1.0.0"),
+		// Not ready.
+		testNode(3, 0, "1.0.0"),
+	}
+	r := testReport(t, nodes, nil, "")
+	deployment := version.MustFromString("2.0.0")
+
+	for _, v := range []struct {
+		threshold float64
+		ready     bool
+	}{
+		{0, true},
+		{0.5, true},
+		// Exactly at the threshold (3 of 5 nodes).
+		{0.6, true},
+		{0.61, false},
+		{1, false},
+	} {
+		rr := buildReadinessReport(r, deployment, testEpoch, nil, v.threshold)
+		if rr.ReadyNodes != 3 || rr.TotalNodes != 5 || rr.NodeReadiness != 0.6 {
+			t.Fatalf("threshold %v: expected 3/5 ready nodes, got %d/%d", v.threshold, rr.ReadyNodes, rr.TotalNodes)
+		}
+		if rr.ReadyEntities != 1 || rr.TotalEntities != 3 {
+			t.Fatalf("threshold %v: expected 1/3 ready entities, got %d/%d", v.threshold, rr.ReadyEntities, rr.TotalEntities)
+		}
+		if rr.IsReady != v.ready {
+			t.Fatalf("threshold %v: expected ready %v, got %v", v.threshold, v.ready, rr.IsReady)
+		}
+	}
+
+	// Nothing is ready without any nodes.
+	rr := buildReadinessReport(testReport(t, nil, nil, ""), deployment, testEpoch, nil, 0)
+	if rr.IsReady {
+		t.Fatalf("empty runtime: expected not ready")
+	}
+}
+
+func TestReadinessNewerVersion(t *testing.T) {
+	nodes := []*node.Node{
+		// Ahead of the deployment: ready, as it is not a laggard.
+		testNode(1, 0, "3.0.0"),
+		testNode(2, 0, "0.10.0"),
+	}
+	r := testReport(t, nodes, nil, "2.0.0")
+	rr := buildReadinessReport(r, version.MustFromString("2.0.0"), testEpoch, nil, 0.5)
+	if rr.ReadyNodes != 1 || rr.ReadyEntities != 1 || !rr.IsReady {
+		t.Fatalf("expected 1 ready node and entity, got %d and %d", rr.ReadyNodes, rr.ReadyEntities)
+	}
+	for _, er := range rr.Entities {
+		isLaggard := false
+		for _, l := range r.Laggards {
+			isLaggard = isLaggard || l.Address == er.Address
+		}
+		if er.IsReady == isLaggard {
+			t.Fatalf("entity %s: ready is %v, but laggard is %v", er.Address, er.IsReady, isLaggard)
+		}
+	}
+
+	validFrom := beacon.EpochTime(testEpoch + 5)
+	rr = buildReadinessReport(r, version.MustFromString("2.0.0"), testEpoch, &validFrom, 0.5)
+	if rr.EpochsLeft == nil || *rr.EpochsLeft != 5 {
+		t.Fatalf("expected 5 epochs left, got %v", rr.EpochsLeft)
+	}
+}
+
+func TestPendingDeployments(t *testing.T) {
+	nodes := []*node.Node{
+		testNode(1, 0, "0.9.0"),
+		testNode(1, 1, "1.0.0", "1.1.0"),
+		testNode(2, 0, "1.0.0"),
+		testNode(3, 0, "0.10.0"),
+	}
+	r := testReport(t, nodes, nil, "")
+	for _, v := range []struct {
+		registered string
+		expected   []string
+	}{
+		// The registered version, and the newer advertised ones.
+		{"1.0.0", []string{"1.0.0", "1.1.0"}},
+		// Checked even if no node advertises it.
+		{"0.9.5", []string{"0.9.5", "0.10.0", "1.0.0", "1.1.0"}},
+		{"2.0.0", []string{"2.0.0"}},
+	} {
+		var deployments []string
+		for _, d := range pendingDeployments(r, version.MustFromString(v.registered)) {
+			deployments = append(deployments, d.String())
+		}
+		if !reflect.DeepEqual(deployments, v.expected) {
+			t.Fatalf("registered %s: expected deployments %v, got %v", v.registered, v.expected, deployments)
+		}
+	}
+}
+
+func TestWriteReadiness(t *testing.T) {
+	nodes := []*node.Node{
+		testNode(1, 0, "1.0.0", "1.1.0"),
+		testNode(2, 0, "1.0.0"),
+	}
+	r := testReport(t, nodes, nil, "")
+	rrs := &readinessReports{
+		Height:            testHeight,
+		Epoch:             testEpoch,
+		RuntimeID:         testRuntimeID,
+		RegisteredVersion: "1.0.0",
+	}
+	for _, d := range pendingDeployments(r, version.MustFromString("1.0.0")) {
+		rrs.Deployments = append(rrs.Deployments, buildReadinessReport(r, d, testEpoch, nil, 0.6))
+	}
+	if n := len(rrs.notReady()); n != 1 {
+		t.Fatalf("expected 1 deployment not ready, got %d", n)
+	}
+
+	for _, format := range reportFormats {
+		var buf bytes.Buffer
+		if err := writeReadiness(&buf, format, rrs); err != nil {
+			t.Fatalf("writeReadiness(%s): %v", format, err)
+		}
+		checkFormat(t, format, buf.Bytes(), "1.0.0", "1.1.0", testAddress(2))
+	}
+}
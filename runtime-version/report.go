@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	metadataRegistry "github.com/oasisprotocol/metadata-registry-tools"
+)
+
+const noEntityName = "<none>"
+
+// runtimeReport is the runtime version report.
+type runtimeReport struct {
+	Height    int64            `json:"height" yaml:"height"`
+	RuntimeID common.Namespace `json:"runtime_id" yaml:"runtime_id"`
+
+	// Kind is the runtime kind (compute or keymanager).
+	Kind string `json:"kind" yaml:"kind"`
+	// Name is the runtime's name, if known.  The metadata registry has
+	// no runtime statements, so this is the metadata registry name of
+	// the entity that registered the runtime, unless overridden.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Owner is the address of the entity that registered the runtime,
+	// and OwnerName is its name from the metadata registry, if any.
+	Owner     string `json:"owner" yaml:"owner"`
+	OwnerName string `json:"owner_name,omitempty" yaml:"owner_name,omitempty"`
+
+	TotalNodes int             `json:"total_nodes" yaml:"total_nodes"`
+	Versions   []*versionStats `json:"versions" yaml:"versions"`
+	Entities   []*entityStats  `json:"entities" yaml:"entities"`
+
+	// LatestVersion is the highest version run by any node.
+	LatestVersion string `json:"latest_version" yaml:"latest_version"`
+
+	// TargetVersion is the version the entities are compared against,
+	// which defaults to the latest version.
+	TargetVersion  string   `json:"target_version" yaml:"target_version"`
+	TargetEntities []string `json:"target_entities" yaml:"target_entities"`
+
+	// Laggards are the entities with at least one node that is behind
+	// the target version (see laggardStats).
+	Laggards []*laggardStats `json:"laggards" yaml:"laggards"`
+
+	// ExpiredVersions and FrozenVersions are the per-version counts of
+	// the nodes excluded from the stats above, and ExcludedNodes are the
+	// nodes themselves.
+	ExpiredVersions []*versionStats `json:"expired_versions" yaml:"expired_versions"`
+	FrozenVersions  []*versionStats `json:"frozen_versions" yaml:"frozen_versions"`
+	ExcludedNodes   []*excludedNode `json:"excluded_nodes" yaml:"excluded_nodes"`
+
+	// Stake is the stake-weighted adoption, if queried.
+	Stake *stakeStats `json:"stake,omitempty" yaml:"stake,omitempty"`
+
+	// Breakdown is the per-version node role and TEE breakdown, if
+	// queried.
+	Breakdown *breakdownStats `json:"breakdown,omitempty" yaml:"breakdown,omitempty"`
+}
+
+// versionStats is the per-version node count.
+type versionStats struct {
+	Version string `json:"version" yaml:"version"`
+	Nodes   int    `json:"nodes" yaml:"nodes"`
+
+	version version.Version
+}
+
+// entityStats is the set of versions run by an entity's nodes.
+type entityStats struct {
+	Address  string   `json:"address" yaml:"address"`
+	Name     string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Versions []string `json:"versions" yaml:"versions"`
+
+	// Escrow is the entity's active escrow balance, if queried.
+	Escrow *quantity.Quantity `json:"escrow,omitempty" yaml:"escrow,omitempty"`
+
+	id    signature.PublicKey
+	meta  *metadataRegistry.EntityMetadata
+	nodes []*nodeVersions
+}
+
+// nodeVersions is the set of versions of the runtime a node advertises.
+type nodeVersions struct {
+	ID       signature.PublicKey `json:"id" yaml:"id"`
+	Versions []string            `json:"versions" yaml:"versions"`
+
+	latest version.Version
+}
+
+// excludedNode is a node excluded from the version stats, as it is not
+// active.
+type excludedNode struct {
+	ID       signature.PublicKey `json:"id" yaml:"id"`
+	Entity   string              `json:"entity" yaml:"entity"`
+	State    nodeState           `json:"state" yaml:"state"`
+	Versions []string            `json:"versions" yaml:"versions"`
+}
+
+// laggardStats is an entity with at least one node that is behind the
+// target version, as in the node advertises only versions older than the
+// target version, and its contact information from the metadata registry.
+// An entity is upgraded iff it is not a laggard, ie: all of its nodes
+// advertise the target version or a newer one.
+type laggardStats struct {
+	Address string `json:"address" yaml:"address"`
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Email   string `json:"email,omitempty" yaml:"email,omitempty"`
+	Twitter string `json:"twitter,omitempty" yaml:"twitter,omitempty"`
+	Keybase string `json:"keybase,omitempty" yaml:"keybase,omitempty"`
+	URL     string `json:"url,omitempty" yaml:"url,omitempty"`
+
+	// Nodes are the entity's nodes that are behind.
+	Nodes []*nodeVersions `json:"nodes" yaml:"nodes"`
+	// UpgradedNodes is the number of the entity's other nodes.
+	UpgradedNodes int `json:"upgraded_nodes" yaml:"upgraded_nodes"`
+}
+
+// contact returns the laggard's contact information, as used by the text
+// and markdown output, or the empty string if there is none.
+func (l *laggardStats) contact() string {
+	var fields []string
+	for _, f := range []struct {
+		name, value string
+	}{
+		{"email", l.Email},
+		{"twitter", l.Twitter},
+		{"keybase", l.Keybase},
+		{"url", l.URL},
+	} {
+		if f.value != "" {
+			fields = append(fields, f.name+": "+f.value)
+		}
+	}
+	return strings.Join(fields, ", ")
+}
+
+// isOnVersion returns true iff any of the entity's nodes run the version.
+func (e *entityStats) isOnVersion(version string) bool {
+	for _, v := range e.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// displayName returns the entity's address followed by its name (if
+// any), as used by the text output.
+func (e *entityStats) displayName() string {
+	name := e.Name
+	if name == "" {
+		name = noEntityName
+	}
+	return e.Address + " " + name
+}
+
+// buildRuntimeReport aggregates the versions of the runtime rt advertised
+// by the active nodes.  If target is nil, the entities are compared
+// against the latest version.
+func buildRuntimeReport(
+	ctx context.Context,
+	gp metadataRegistry.Provider,
+	height int64,
+	rt *registryAPI.Runtime,
+	nodes []*node.Node,
+	states nodeStates,
+	target *version.Version,
+) *runtimeReport {
+	entityVersions := make(map[signature.PublicKey]map[version.Version]bool)
+	entityNodes := make(map[signature.PublicKey][]*nodeVersions)
+	versionCounts := make(map[version.Version]int)
+	excludedCounts := map[nodeState]map[version.Version]int{
+		nodeExpired: make(map[version.Version]int),
+		nodeFrozen:  make(map[version.Version]int),
+	}
+	ownerName := entityMetadata(ctx, gp, rt.EntityID).Name
+	r := &runtimeReport{
+		Height:         height,
+		RuntimeID:      rt.ID,
+		Kind:           rt.Kind.String(),
+		Name:           ownerName,
+		Owner:          staking.NewAddress(rt.EntityID).String(),
+		OwnerName:      ownerName,
+		TargetEntities: []string{},
+		Laggards:       []*laggardStats{},
+		ExcludedNodes:  []*excludedNode{},
+	}
+
+	for _, node := range nodes {
+		if state := states.get(node.ID); state != nodeActive {
+			var en *excludedNode
+			for _, runtime := range node.Runtimes {
+				if runtime.ID != rt.ID {
+					continue
+				}
+				if en == nil {
+					en = &excludedNode{
+						ID:     node.ID,
+						Entity: staking.NewAddress(node.EntityID).String(),
+						State:  state,
+					}
+					r.ExcludedNodes = append(r.ExcludedNodes, en)
+				}
+				en.Versions = append(en.Versions, runtime.Version.String())
+				excludedCounts[state][runtime.Version]++
+			}
+			continue
+		}
+
+		var nv *nodeVersions
+		for _, runtime := range node.Runtimes {
+			if runtime.ID == rt.ID {
+				// Nodes can advertise multiple versions during upgrades.
+				if nv == nil {
+					nv = &nodeVersions{ID: node.ID}
+					entityNodes[node.EntityID] = append(entityNodes[node.EntityID], nv)
+				}
+				nv.Versions = append(nv.Versions, runtime.Version.String())
+				if runtime.Version.ToU64() > nv.latest.ToU64() {
+					nv.latest = runtime.Version
+				}
+
+				versionCounts[runtime.Version] = versionCounts[runtime.Version] + 1
+				r.TotalNodes += 1
+
+				// Store runtime version info for current entity.
+				if _, ok := entityVersions[node.EntityID]; !ok {
+					entityVersions[node.EntityID] = make(map[version.Version]bool)
+				}
+				entityVersions[node.EntityID][runtime.Version] = true
+			}
+		}
+	}
+
+	// Node version stats
+	r.Versions = newVersionStats(versionCounts)
+	versions := make([]version.Version, 0, len(r.Versions))
+	for _, v := range r.Versions {
+		versions = append(versions, v.version)
+	}
+	r.ExpiredVersions = newVersionStats(excludedCounts[nodeExpired])
+	r.FrozenVersions = newVersionStats(excludedCounts[nodeFrozen])
+	sort.Slice(r.ExcludedNodes, func(i, j int) bool {
+		return r.ExcludedNodes[i].ID.String() < r.ExcludedNodes[j].ID.String()
+	})
+	if len(versions) > 0 {
+		r.LatestVersion = versions[len(versions)-1].String()
+	}
+	if target == nil && len(versions) > 0 {
+		target = &versions[len(versions)-1]
+	}
+	if target != nil {
+		r.TargetVersion = target.String()
+	}
+
+	// Entity version stats
+	for entity, entityVersions := range entityVersions {
+		meta := entityMetadata(ctx, gp, entity)
+		e := &entityStats{
+			Address: staking.NewAddress(entity).String(),
+			Name:    meta.Name,
+			id:      entity,
+			meta:    meta,
+			nodes:   entityNodes[entity],
+		}
+		sort.Slice(e.nodes, func(i, j int) bool {
+			return e.nodes[i].ID.String() < e.nodes[j].ID.String()
+		})
+		for _, v := range versions {
+			if entityVersions[v] {
+				e.Versions = append(e.Versions, v.String())
+			}
+		}
+		r.Entities = append(r.Entities, e)
+	}
+	sort.Slice(r.Entities, func(i, j int) bool {
+		return r.Entities[i].Address < r.Entities[j].Address
+	})
+
+	// Entities running target version
+	for _, e := range r.Entities {
+		if e.isOnVersion(r.TargetVersion) {
+			r.TargetEntities = append(r.TargetEntities, e.Address)
+		}
+	}
+
+	// Entities running old versions
+	if target != nil {
+		for _, e := range r.Entities {
+			if l := newLaggardStats(e, *target); l != nil {
+				r.Laggards = append(r.Laggards, l)
+			}
+		}
+	}
+
+	return r
+}
+
+// newLaggardStats returns the entity's laggard stats, or nil if none of
+// its nodes are behind target.  A node is behind iff the latest version it
+// advertises is older than target.
+func newLaggardStats(e *entityStats, target version.Version) *laggardStats {
+	l := &laggardStats{
+		Address: e.Address,
+		Name:    e.Name,
+		Email:   e.meta.Email,
+		Twitter: e.meta.Twitter,
+		Keybase: e.meta.Keybase,
+		URL:     e.meta.URL,
+	}
+	for _, nv := range e.nodes {
+		if nv.latest.ToU64() < target.ToU64() {
+			l.Nodes = append(l.Nodes, nv)
+		} else {
+			l.UpgradedNodes++
+		}
+	}
+	if len(l.Nodes) == 0 {
+		return nil
+	}
+	return l
+}
+
+// newVersionStats returns the per-version stats, in ascending version
+// order.
+func newVersionStats(counts map[version.Version]int) []*versionStats {
+	versions := make([]version.Version, 0, len(counts))
+	for v := range counts {
+		versions = append(versions, v)
+	}
+	sortVersions(versions)
+
+	stats := make([]*versionStats, 0, len(versions))
+	for _, v := range versions {
+		stats = append(stats, &versionStats{
+			Version: v.String(),
+			Nodes:   counts[v],
+			version: v,
+		})
+	}
+	return stats
+}
+
+// sortVersions sorts the versions in ascending semantic version order.
+func sortVersions(versions []version.Version) {
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].ToU64() < versions[j].ToU64()
+	})
+}
+
+// entityMetadata returns the entity's metadata from the metadata
+// registry, or empty metadata if the entity is not in the registry (or
+// gp is nil).
+func entityMetadata(ctx context.Context, gp metadataRegistry.Provider, entity signature.PublicKey) *metadataRegistry.EntityMetadata {
+	if gp == nil {
+		return &metadataRegistry.EntityMetadata{}
+	}
+	meta, err := gp.GetEntity(ctx, entity)
+	if err != nil {
+		return &metadataRegistry.EntityMetadata{}
+	}
+	return meta
+}
+
+// runtimeDisplayName returns the runtime's ID followed by its kind and
+// name (if any), as used by the text output.
+func (r *runtimeReport) runtimeDisplayName() string {
+	if r.Name == "" {
+		return fmt.Sprintf("%s (%s)", r.RuntimeID, r.Kind)
+	}
+	return fmt.Sprintf("%s (%s, %s)", r.RuntimeID, r.Kind, r.Name)
+}
+
+// parseTargetVersion parses the target version, returning nil if none
+// was provided.
+func parseTargetVersion(s string) (*version.Version, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, err := version.FromString(s)
+	if err != nil {
+		return nil, fmt.Errorf("malformed target version: %s", s)
+	}
+	return &v, nil
+}
+
+// parseRuntimeNames parses runtime names, each provided as `<id>=<name>`.
+func parseRuntimeNames(raw []string) (map[common.Namespace]string, error) {
+	names := make(map[common.Namespace]string)
+	for _, v := range raw {
+		split := strings.SplitN(v, "=", 2)
+		if len(split) != 2 || split[1] == "" {
+			return nil, fmt.Errorf("malformed runtime name: %s", v)
+		}
+		var id common.Namespace
+		if err := id.UnmarshalHex(split[0]); err != nil {
+			return nil, fmt.Errorf("malformed runtime ID: %s", split[0])
+		}
+		names[id] = split[1]
+	}
+	return names, nil
+}
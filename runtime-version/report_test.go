@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/oasisprotocol/oasis-core/go/common"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	metadataRegistry "github.com/oasisprotocol/metadata-registry-tools"
+)
+
+const testHeight = 1234
+
+var testRuntimeID = common.NewTestNamespaceFromSeed([]byte("runtime-version test"), 0)
+
+func testRuntime() *registryAPI.Runtime {
+	return &registryAPI.Runtime{
+		ID:       testRuntimeID,
+		EntityID: testEntityID(0xff),
+		Kind:     registryAPI.KindCompute,
+	}
+}
+
+func testEntityID(i byte) signature.PublicKey {
+	var id signature.PublicKey
+	id[0] = i
+	return id
+}
+
+func testNodeID(entity, i byte) signature.PublicKey {
+	var id signature.PublicKey
+	id[0], id[1] = entity, i
+	return id
+}
+
+func testAddress(entity byte) string {
+	return staking.NewAddress(testEntityID(entity)).String()
+}
+
+// testNode returns a node of the entity, advertising the versions of the
+// test runtime.
+func testNode(entity, i byte, versions ...string) *node.Node {
+	n := &node.Node{
+		ID:       testNodeID(entity, i),
+		EntityID: testEntityID(entity),
+		Roles:    node.RoleComputeWorker,
+	}
+	for _, v := range versions {
+		n.Runtimes = append(n.Runtimes, &node.Runtime{
+			ID:      testRuntimeID,
+			Version: version.MustFromString(v),
+		})
+	}
+	return n
+}
+
+func testReport(t *testing.T, nodes []*node.Node, states nodeStates, target string) *runtimeReport {
+	var tv *version.Version
+	if target != "" {
+		v := version.MustFromString(target)
+		tv = &v
+	}
+	return buildRuntimeReport(context.Background(), nil, testHeight, testRuntime(), nodes, states, tv)
+}
+
+func TestLaggards(t *testing.T) {
+	nodes := []*node.Node{
+		// Partially upgraded: a laggard.
+		testNode(1, 0, "1.0.0"),
+		testNode(1, 1, "2.0.0"),
+		// Mid-upgrade node, advertising both versions: upgraded.
+		testNode(2, 0, "1.0.0", "2.0.0"),
+		// Not upgraded at all: a laggard.
+		testNode(3, 0, "1.0.0"),
+		testNode(3, 1, "1.5.0"),
+		// Ahead of the target: upgraded.
+		testNode(4, 0, "3.0.0"),
+	}
+	r := testReport(t, nodes, nil, "2.0.0")
+
+	type laggard struct {
+		address       string
+		nodes         []signature.PublicKey
+		upgradedNodes int
+	}
+	var laggards []laggard
+	for _, l := range r.Laggards {
+		lg := laggard{
+			address:       l.Address,
+			upgradedNodes: l.UpgradedNodes,
+		}
+		for _, nv := range l.Nodes {
+			lg.nodes = append(lg.nodes, nv.ID)
+		}
+		laggards = append(laggards, lg)
+	}
+
+	expected := []laggard{
+		{testAddress(1), []signature.PublicKey{testNodeID(1, 0)}, 1},
+		{testAddress(3), []signature.PublicKey{testNodeID(3, 0), testNodeID(3, 1)}, 0},
+	}
+	// The laggards are in entity address order.
+	if expected[0].address > expected[1].address {
+		expected[0], expected[1] = expected[1], expected[0]
+	}
+	if !reflect.DeepEqual(laggards, expected) {
+		t.Fatalf("laggards: expected %+v, got %+v", expected, laggards)
+	}
+
+	// Without a target version, the latest version is the target.
+	r = testReport(t, nodes, nil, "")
+	if r.TargetVersion != "3.0.0" {
+		t.Fatalf("default target version: expected 3.0.0, got %s", r.TargetVersion)
+	}
+	if n := len(r.Laggards); n != 3 {
+		t.Fatalf("laggards behind 3.0.0: expected 3, got %d", n)
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	var versions []version.Version
+	for _, s := range []string{"1.0.0", "0.10.0", "0.9.0", "0.10.1", "0.9.10"} {
+		versions = append(versions, version.MustFromString(s))
+	}
+	sortVersions(versions)
+
+	var sorted []string
+	for _, v := range versions {
+		sorted = append(sorted, v.String())
+	}
+	expected := []string{"0.9.0", "0.9.10", "0.10.0", "0.10.1", "1.0.0"}
+	if !reflect.DeepEqual(sorted, expected) {
+		t.Fatalf("sortVersions: expected %v, got %v", expected, sorted)
+	}
+}
+
+func TestParseTargetVersion(t *testing.T) {
+	for _, v := range []struct {
+		raw      string
+		expected string
+		valid    bool
+	}{
+		{"", "", true},
+		{"1.2.3", "1.2.3", true},
+		{"0.10.0", "0.10.0", true},
+		{"21.1", "21.1.0", true},
+		{"1.x.0", "", false},
+		{"not-a-version", "", false},
+	} {
+		target, err := parseTargetVersion(v.raw)
+		switch {
+		case !v.valid:
+			if err == nil {
+				t.Fatalf("parseTargetVersion(%q): failed to reject malformed version", v.raw)
+			}
+		case err != nil:
+			t.Fatalf("parseTargetVersion(%q): %v", v.raw, err)
+		case v.expected == "":
+			if target != nil {
+				t.Fatalf("parseTargetVersion(%q): expected no target, got %s", v.raw, target)
+			}
+		case target == nil || target.String() != v.expected:
+			t.Fatalf("parseTargetVersion(%q): expected %s, got %v", v.raw, v.expected, target)
+		}
+	}
+}
+
+// testProvider is a metadata registry provider serving the given entity
+// names.
+type testProvider struct {
+	metadataRegistry.Provider
+
+	names map[signature.PublicKey]string
+}
+
+func (p *testProvider) GetEntity(ctx context.Context, id signature.PublicKey) (*metadataRegistry.EntityMetadata, error) {
+	name, ok := p.names[id]
+	if !ok {
+		return nil, fmt.Errorf("no such entity: %s", id)
+	}
+	return &metadataRegistry.EntityMetadata{Name: name}, nil
+}
+
+func TestRuntimeName(t *testing.T) {
+	rt := testRuntime()
+	for _, v := range []struct {
+		names    map[signature.PublicKey]string
+		expected string
+	}{
+		{map[signature.PublicKey]string{rt.EntityID: "Test Owner"}, "Test Owner"},
+		{map[signature.PublicKey]string{testEntityID(1): "Someone Else"}, ""},
+	} {
+		gp := &testProvider{names: v.names}
+		r := buildRuntimeReport(context.Background(), gp, testHeight, rt, nil, nil, nil)
+		if r.Name != v.expected || r.OwnerName != v.expected {
+			t.Fatalf("runtime name: expected %q, got %q (owner %q)", v.expected, r.Name, r.OwnerName)
+		}
+	}
+}
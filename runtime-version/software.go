@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+
+	beacon "github.com/oasisprotocol/oasis-core/go/beacon/api"
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	cmnGrpc "github.com/oasisprotocol/oasis-core/go/common/grpc"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/version"
+	consensusAPI "github.com/oasisprotocol/oasis-core/go/consensus/api"
+	controlAPI "github.com/oasisprotocol/oasis-core/go/control/api"
+	cmdCommon "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common"
+	cmdGrpc "github.com/oasisprotocol/oasis-core/go/oasis-node/cmd/common/grpc"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+	schedulerAPI "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+
+	metadataRegistry "github.com/oasisprotocol/metadata-registry-tools"
+)
+
+const (
+	// CfgSoftwareControlAddress configures the control endpoints of
+	// additional nodes to query for their software version.
+	cfgSoftwareControlAddress = "control-address"
+
+	// CfgSoftwareValidators configures reporting on the validators only.
+	cfgSoftwareValidators = "validators"
+
+	softwareUnknown = "unknown"
+)
+
+var (
+	softwareCmdFlags = flag.NewFlagSet("", flag.ContinueOnError)
+
+	softwareCmd = &cobra.Command{
+		Use:   "software",
+		Short: "query oasis-core software versions",
+		Args:  cobra.NoArgs,
+		Run:   doSoftware,
+	}
+)
+
+// softwareReport is the oasis-core software version report.
+type softwareReport struct {
+	Height         int64 `json:"height" yaml:"height"`
+	ValidatorsOnly bool  `json:"validators_only" yaml:"validators_only"`
+
+	// TotalNodes is the number of registered (active) nodes, and
+	// UnknownNodes the number of them whose software version was not
+	// queried.
+	TotalNodes   int `json:"total_nodes" yaml:"total_nodes"`
+	UnknownNodes int `json:"unknown_nodes" yaml:"unknown_nodes"`
+
+	Versions []*softwareVersionStats `json:"versions" yaml:"versions"`
+	Entities []*softwareEntityStats  `json:"entities" yaml:"entities"`
+}
+
+// softwareVersionStats is the number of nodes and entities running a
+// software version.
+type softwareVersionStats struct {
+	Version  string `json:"version" yaml:"version"`
+	Nodes    int    `json:"nodes" yaml:"nodes"`
+	Entities int    `json:"entities" yaml:"entities"`
+}
+
+// softwareEntityStats is the software versions run by an entity's nodes.
+type softwareEntityStats struct {
+	Address  string          `json:"address" yaml:"address"`
+	Name     string          `json:"name,omitempty" yaml:"name,omitempty"`
+	Versions []string        `json:"versions" yaml:"versions"`
+	Nodes    []*softwareNode `json:"nodes" yaml:"nodes"`
+}
+
+// displayName returns the entity's address followed by its name (if
+// any), as used by the text output.
+func (e *softwareEntityStats) displayName() string {
+	name := e.Name
+	if name == "" {
+		name = noEntityName
+	}
+	return e.Address + " " + name
+}
+
+// isOnVersion returns true iff any of the entity's nodes runs the version.
+func (e *softwareEntityStats) isOnVersion(version string) bool {
+	for _, v := range e.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// softwareNode is the software version of a node, which is unknown if
+// the node was not queried.
+type softwareNode struct {
+	ID      signature.PublicKey `json:"id" yaml:"id"`
+	Version string              `json:"version" yaml:"version"`
+}
+
+// buildSoftwareReport aggregates the software versions of the active
+// nodes (or of the active validators, if validators is not nil).
+func buildSoftwareReport(
+	ctx context.Context,
+	gp metadataRegistry.Provider,
+	height int64,
+	nodes []*node.Node,
+	states nodeStates,
+	validators map[signature.PublicKey]bool,
+	versions map[signature.PublicKey]string,
+) *softwareReport {
+	r := &softwareReport{
+		Height:         height,
+		ValidatorsOnly: validators != nil,
+		Versions:       []*softwareVersionStats{},
+		Entities:       []*softwareEntityStats{},
+	}
+
+	byVersion := make(map[string]*softwareVersionStats)
+	byEntity := make(map[signature.PublicKey]*softwareEntityStats)
+	for _, n := range nodes {
+		if states.get(n.ID) != nodeActive {
+			continue
+		}
+		if validators != nil && !validators[n.ID] {
+			continue
+		}
+		r.TotalNodes++
+
+		e, ok := byEntity[n.EntityID]
+		if !ok {
+			e = &softwareEntityStats{
+				Address:  staking.NewAddress(n.EntityID).String(),
+				Name:     entityMetadata(ctx, gp, n.EntityID).Name,
+				Versions: []string{},
+			}
+			byEntity[n.EntityID] = e
+			r.Entities = append(r.Entities, e)
+		}
+
+		v, ok := versions[n.ID]
+		if !ok {
+			r.UnknownNodes++
+			e.Nodes = append(e.Nodes, &softwareNode{ID: n.ID, Version: softwareUnknown})
+			continue
+		}
+		e.Nodes = append(e.Nodes, &softwareNode{ID: n.ID, Version: v})
+
+		vs, ok := byVersion[v]
+		if !ok {
+			vs = &softwareVersionStats{Version: v}
+			byVersion[v] = vs
+			r.Versions = append(r.Versions, vs)
+		}
+		vs.Nodes++
+		if !e.isOnVersion(v) {
+			e.Versions = append(e.Versions, v)
+			vs.Entities++
+		}
+	}
+
+	sort.Slice(r.Versions, func(i, j int) bool {
+		return softwareVersionLess(r.Versions[i].Version, r.Versions[j].Version)
+	})
+	sort.Slice(r.Entities, func(i, j int) bool {
+		return r.Entities[i].Address < r.Entities[j].Address
+	})
+	for _, e := range r.Entities {
+		sort.Slice(e.Versions, func(i, j int) bool {
+			return softwareVersionLess(e.Versions[i], e.Versions[j])
+		})
+		sort.Slice(e.Nodes, func(i, j int) bool {
+			return e.Nodes[i].ID.String() < e.Nodes[j].ID.String()
+		})
+	}
+
+	return r
+}
+
+// softwareVersionLess orders software versions semantically, ignoring
+// any pre-release or build suffix (as version.FromString does), and then
+// lexicographically.  Malformed versions sort first.
+func softwareVersionLess(a, b string) bool {
+	va, _ := version.FromString(a)
+	vb, _ := version.FromString(b)
+	if va.ToU64() != vb.ToU64() {
+		return va.ToU64() < vb.ToU64()
+	}
+	return a < b
+}
+
+// fetchSoftwareVersions queries the software version of the nodes over
+// their control API, keyed by the node ID.
+func fetchSoftwareVersions(ctx context.Context, conns map[string]*grpc.ClientConn) (map[signature.PublicKey]string, error) {
+	versions := make(map[signature.PublicKey]string)
+	for addr, conn := range conns {
+		status, err := controlAPI.NewNodeControllerClient(conn).GetStatus(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query node status from %s: %w", addr, err)
+		}
+		versions[status.Identity.Node] = status.SoftwareVersion
+	}
+	return versions, nil
+}
+
+func doDialControl(addr string) *grpc.ClientConn {
+	if _, err := os.Stat(addr); err == nil {
+		addr = "unix:" + addr
+	}
+	conn, err := cmnGrpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	return conn
+}
+
+func doSoftware(cmd *cobra.Command, args []string) {
+	ctx := context.Background()
+
+	doInitGenesis()
+
+	format := doGetFormat()
+	conn := doConnect(cmd)
+
+	consensus := consensusAPI.NewConsensusClient(conn)
+	reg := registryAPI.NewRegistryClient(conn)
+
+	height := doGetHeight(ctx, consensus, viper.GetInt64(cfgHeight))
+
+	nodes, err := reg.GetNodes(ctx, height)
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+	states := doGetNodeStates(ctx, reg, beacon.NewBeaconClient(conn), height, nodes)
+
+	var validators map[signature.PublicKey]bool
+	if viper.GetBool(cfgSoftwareValidators) {
+		vs, err := schedulerAPI.NewSchedulerClient(conn).GetValidators(ctx, height)
+		if err != nil {
+			cmdCommon.EarlyLogAndExit(fmt.Errorf("failed to query validators: %w", err))
+		}
+		validators = make(map[signature.PublicKey]bool)
+		for _, v := range vs {
+			validators[v.ID] = true
+		}
+	}
+
+	// The node descriptors do not carry the software version, so it is
+	// queried from the control API of the node at --address, and of any
+	// other node provided.
+	conns := map[string]*grpc.ClientConn{
+		viper.GetString(cmdGrpc.CfgAddress): conn,
+	}
+	for _, addr := range viper.GetStringSlice(cfgSoftwareControlAddress) {
+		conns[addr] = doDialControl(addr)
+	}
+	versions, err := fetchSoftwareVersions(ctx, conns)
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	gp, err := metadataRegistry.NewGitProvider(metadataRegistry.NewGitConfig())
+	if err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+
+	r := buildSoftwareReport(ctx, gp, height, nodes, states, validators, versions)
+	if err = writeSoftware(os.Stdout, format, r); err != nil {
+		cmdCommon.EarlyLogAndExit(err)
+	}
+}
+
+func init() {
+	softwareCmdFlags.StringSlice(
+		cfgSoftwareControlAddress,
+		nil,
+		"control API address of another node to query for its software version (may be repeated)",
+	)
+	softwareCmdFlags.Bool(cfgSoftwareValidators, false, "report on the validators only")
+	_ = viper.BindPFlags(softwareCmdFlags)
+	softwareCmd.Flags().AddFlagSet(softwareCmdFlags)
+	softwareCmd.Flags().AddFlagSet(heightFlags)
+
+	queryCmd.AddCommand(softwareCmd)
+}
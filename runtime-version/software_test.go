@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+)
+
+func testSoftwareReport(validators map[signature.PublicKey]bool) *softwareReport {
+	nodes := []*node.Node{
+		testNode(1, 0),
+		testNode(1, 1),
+		testNode(2, 0),
+		testNode(2, 1),
+		// Not queried.
+		testNode(3, 0),
+		// Inactive.
+		testNode(4, 0),
+	}
+	states := nodeStates{
+		testNodeID(4, 0): nodeExpired,
+	}
+	versions := map[signature.PublicKey]string{
+		testNodeID(1, 0): "21.3.10",
+		testNodeID(1, 1): "21.3.9",
+		testNodeID(2, 0): "21.3.10",
+		testNodeID(2, 1): "22.0.0-rc1",
+		testNodeID(4, 0): "20.12.3",
+		// Not registered.
+		testNodeID(5, 0): "21.3.10",
+	}
+	gp := &testProvider{names: map[signature.PublicKey]string{testEntityID(1): "Entity One"}}
+	return buildSoftwareReport(context.Background(), gp, testHeight, nodes, states, validators, versions)
+}
+
+func TestSoftwareReport(t *testing.T) {
+	r := testSoftwareReport(nil)
+	if r.TotalNodes != 5 || r.UnknownNodes != 1 {
+		t.Fatalf("expected 5 nodes (1 unknown), got %d (%d unknown)", r.TotalNodes, r.UnknownNodes)
+	}
+
+	// Versions are ordered semantically, not lexicographically.
+	expected := []softwareVersionStats{
+		{Version: "21.3.9", Nodes: 1, Entities: 1},
+		{Version: "21.3.10", Nodes: 2, Entities: 2},
+		{Version: "22.0.0-rc1", Nodes: 1, Entities: 1},
+	}
+	var versions []softwareVersionStats
+	for _, v := range r.Versions {
+		versions = append(versions, *v)
+	}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Fatalf("expected versions %+v, got %+v", expected, versions)
+	}
+
+	entities := make(map[string]*softwareEntityStats)
+	for i, e := range r.Entities {
+		if i > 0 && r.Entities[i-1].Address >= e.Address {
+			t.Fatalf("entities not ordered by address")
+		}
+		entities[e.Address] = e
+	}
+	if len(entities) != 3 {
+		t.Fatalf("expected 3 entities, got %d", len(entities))
+	}
+	e := entities[testAddress(1)]
+	if e == nil || e.Name != "Entity One" {
+		t.Fatalf("entity 1: expected its metadata registry name, got %+v", e)
+	}
+	if !reflect.DeepEqual(e.Versions, []string{"21.3.9", "21.3.10"}) {
+		t.Fatalf("entity 1: unexpected versions %v", e.Versions)
+	}
+	if unknown := entities[testAddress(3)].Nodes[0]; unknown.ID != testNodeID(3, 0) || unknown.Version != softwareUnknown {
+		t.Fatalf("entity 3: expected an unknown version, got %s", unknown.Version)
+	}
+}
+
+func TestSoftwareReportValidators(t *testing.T) {
+	r := testSoftwareReport(map[signature.PublicKey]bool{
+		testNodeID(1, 0): true,
+		testNodeID(3, 0): true,
+		// Inactive validators are excluded too.
+		testNodeID(4, 0): true,
+	})
+	if !r.ValidatorsOnly || r.TotalNodes != 2 || r.UnknownNodes != 1 {
+		t.Fatalf("expected 2 validators (1 unknown), got %d (%d unknown)", r.TotalNodes, r.UnknownNodes)
+	}
+	if len(r.Versions) != 1 || r.Versions[0].Version != "21.3.10" || r.Versions[0].Nodes != 1 {
+		t.Fatalf("expected 1 validator running 21.3.10, got %+v", r.Versions)
+	}
+	if len(r.Entities) != 2 {
+		t.Fatalf("expected 2 entities, got %d", len(r.Entities))
+	}
+}
+
+func TestWriteSoftware(t *testing.T) {
+	r := testSoftwareReport(nil)
+	for _, format := range reportFormats {
+		var buf bytes.Buffer
+		if err := writeSoftware(&buf, format, r); err != nil {
+			t.Fatalf("writeSoftware(%s): %v", format, err)
+		}
+		checkFormat(t, format, buf.Bytes(), "21.3.10", "Entity One", testAddress(3))
+	}
+	if err := writeSoftware(&bytes.Buffer{}, "xml", r); err == nil {
+		t.Fatalf("writeSoftware: failed to reject unsupported format")
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	registryAPI "github.com/oasisprotocol/oasis-core/go/registry/api"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+	staking "github.com/oasisprotocol/oasis-core/go/staking/api"
+)
+
+// stakeInfo is the stake of the registered entities, and the validator
+// set, at a height.
+type stakeInfo struct {
+	escrow      map[signature.PublicKey]*quantity.Quantity
+	totalEscrow quantity.Quantity
+
+	validators []*scheduler.Validator
+	nodeEntity map[signature.PublicKey]signature.PublicKey
+}
+
+// stakeStats is the stake-weighted adoption of the runtime's versions.
+type stakeStats struct {
+	// TotalEscrow is the active escrow balance of all registered
+	// entities.
+	TotalEscrow quantity.Quantity `json:"total_escrow" yaml:"total_escrow"`
+	Versions    []*versionStake   `json:"versions" yaml:"versions"`
+
+	// UpgradedEscrow is the active escrow balance of the upgraded
+	// entities, ie: the entities that are not laggards, all of whose
+	// nodes advertise the target version or a newer one (see
+	// laggardStats).
+	UpgradedEscrow quantity.Quantity `json:"upgraded_escrow" yaml:"upgraded_escrow"`
+	UpgradedShare  float64           `json:"upgraded_share" yaml:"upgraded_share"`
+
+	// TotalVotingPower is the voting power of the validator set, and
+	// UpgradedVotingPower is the voting power of the validators whose
+	// entities are upgraded.
+	TotalVotingPower    int64   `json:"total_voting_power" yaml:"total_voting_power"`
+	UpgradedVotingPower int64   `json:"upgraded_voting_power" yaml:"upgraded_voting_power"`
+	UpgradedVotingShare float64 `json:"upgraded_voting_share" yaml:"upgraded_voting_share"`
+}
+
+// versionStake is the active escrow balance attributed to the nodes
+// running a version.  Each entity's escrow is split evenly over its nodes,
+// and each node's share evenly over the versions it advertises, so that
+// the escrow of all versions adds up to the escrow of the entities
+// running the runtime.
+type versionStake struct {
+	Version string            `json:"version" yaml:"version"`
+	Escrow  quantity.Quantity `json:"escrow" yaml:"escrow"`
+	Share   float64           `json:"share" yaml:"share"`
+}
+
+func fetchStakeInfo(
+	ctx context.Context,
+	reg registryAPI.Backend,
+	stakingBackend staking.Backend,
+	sched scheduler.Backend,
+	height int64,
+	nodes []*node.Node,
+) (*stakeInfo, error) {
+	info := &stakeInfo{
+		escrow:     make(map[signature.PublicKey]*quantity.Quantity),
+		nodeEntity: make(map[signature.PublicKey]signature.PublicKey),
+	}
+
+	entities, err := reg.GetEntities(ctx, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entities: %w", err)
+	}
+	for _, entity := range entities {
+		acct, err := stakingBackend.Account(ctx, &staking.OwnerQuery{
+			Height: height,
+			Owner:  staking.NewAddress(entity.ID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query account of entity %s: %w", entity.ID, err)
+		}
+		escrow := acct.Escrow.Active.Balance.Clone()
+		info.escrow[entity.ID] = escrow
+		if err = info.totalEscrow.Add(escrow); err != nil {
+			return nil, err
+		}
+	}
+
+	if info.validators, err = sched.GetValidators(ctx, height); err != nil {
+		return nil, fmt.Errorf("failed to query validators: %w", err)
+	}
+	for _, n := range nodes {
+		info.nodeEntity[n.ID] = n.EntityID
+	}
+
+	return info, nil
+}
+
+// applyStake adds the entities' escrow, and the stake-weighted adoption,
+// to the report.
+func (r *runtimeReport) applyStake(info *stakeInfo) {
+	laggards := make(map[string]bool)
+	for _, l := range r.Laggards {
+		laggards[l.Address] = true
+	}
+	upgraded := make(map[signature.PublicKey]bool)
+
+	s := &stakeStats{
+		TotalEscrow: *info.totalEscrow.Clone(),
+	}
+	versionEscrow := make(map[string]*big.Rat)
+	for _, v := range r.Versions {
+		versionEscrow[v.Version] = new(big.Rat)
+	}
+	for _, e := range r.Entities {
+		escrow, ok := info.escrow[e.id]
+		if !ok {
+			escrow = quantity.NewQuantity()
+		}
+		e.Escrow = escrow.Clone()
+
+		for _, nv := range e.nodes {
+			share := new(big.Rat).SetFrac(
+				escrow.ToBigInt(),
+				big.NewInt(int64(len(e.nodes)*len(nv.Versions))),
+			)
+			for _, v := range nv.Versions {
+				versionEscrow[v].Add(versionEscrow[v], share)
+			}
+		}
+		if !laggards[e.Address] {
+			upgraded[e.id] = true
+			_ = s.UpgradedEscrow.Add(escrow)
+		}
+	}
+	for _, v := range r.Versions {
+		vs := &versionStake{
+			Version: v.Version,
+			Share:   ratShare(versionEscrow[v.Version], &s.TotalEscrow),
+		}
+		// Round down, the share is exact.
+		escrow := new(big.Int).Quo(versionEscrow[v.Version].Num(), versionEscrow[v.Version].Denom())
+		_ = vs.Escrow.FromBigInt(escrow)
+		s.Versions = append(s.Versions, vs)
+	}
+	s.UpgradedShare = quantityShare(&s.UpgradedEscrow, &s.TotalEscrow)
+
+	for _, v := range info.validators {
+		s.TotalVotingPower += v.VotingPower
+		if upgraded[info.nodeEntity[v.ID]] {
+			s.UpgradedVotingPower += v.VotingPower
+		}
+	}
+	if s.TotalVotingPower > 0 {
+		s.UpgradedVotingShare = float64(s.UpgradedVotingPower) / float64(s.TotalVotingPower)
+	}
+
+	r.Stake = s
+}
+
+// quantityShare returns n / total.
+func quantityShare(n, total *quantity.Quantity) float64 {
+	return ratShare(new(big.Rat).SetInt(n.ToBigInt()), total)
+}
+
+// ratShare returns n / total.
+func ratShare(n *big.Rat, total *quantity.Quantity) float64 {
+	if total.IsZero() {
+		return 0
+	}
+	share, _ := new(big.Rat).Quo(n, new(big.Rat).SetInt(total.ToBigInt())).Float64()
+	return share
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oasisprotocol/oasis-core/go/common/crypto/signature"
+	"github.com/oasisprotocol/oasis-core/go/common/node"
+	"github.com/oasisprotocol/oasis-core/go/common/quantity"
+	scheduler "github.com/oasisprotocol/oasis-core/go/scheduler/api"
+)
+
+func testStakeInfo(nodes []*node.Node, escrow map[byte]uint64, votingPower map[signature.PublicKey]int64) *stakeInfo {
+	info := &stakeInfo{
+		escrow:     make(map[signature.PublicKey]*quantity.Quantity),
+		nodeEntity: make(map[signature.PublicKey]signature.PublicKey),
+	}
+	for entity, amount := range escrow {
+		q := quantity.NewFromUint64(amount)
+		info.escrow[testEntityID(entity)] = q
+		_ = info.totalEscrow.Add(q)
+	}
+	for _, n := range nodes {
+		info.nodeEntity[n.ID] = n.EntityID
+		if vp, ok := votingPower[n.ID]; ok {
+			info.validators = append(info.validators, &scheduler.Validator{
+				ID:          n.ID,
+				VotingPower: vp,
+			})
+		}
+	}
+	return info
+}
+
+func TestApplyStake(t *testing.T) {
+	nodes := []*node.Node{
+		// Runs two versions, over two nodes (one of them mid-upgrade).
+		testNode(1, 0, "1.0.0"),
+		testNode(1, 1, "1.0.0", "2.0.0"),
+		testNode(2, 0, "2.0.0"),
+	}
+	info := testStakeInfo(
+		nodes,
+		map[byte]uint64{
+			1: 300,
+			2: 100,
+			// Registered, but not running the runtime.
+			3: 600,
+		},
+		map[signature.PublicKey]int64{
+			testNodeID(1, 0): 30,
+			testNodeID(2, 0): 10,
+		},
+	)
+
+	r := testReport(t, nodes, nil, "2.0.0")
+	r.applyStake(info)
+	s := r.Stake
+
+	if s.TotalEscrow.String() != "1000" {
+		t.Fatalf("total escrow: expected 1000, got %s", s.TotalEscrow)
+	}
+
+	// Entity 1's escrow is split over its nodes (150 each), and the
+	// second node's share over its versions (75 each).
+	expected := map[string]struct {
+		escrow string
+		share  float64
+	}{
+		"1.0.0": {"225", 0.225},
+		"2.0.0": {"175", 0.175},
+	}
+	if len(s.Versions) != len(expected) {
+		t.Fatalf("unexpected number of versions: %d", len(s.Versions))
+	}
+	var totalShare float64
+	for _, v := range s.Versions {
+		e := expected[v.Version]
+		if v.Escrow.String() != e.escrow || math.Abs(v.Share-e.share) > 1e-9 {
+			t.Fatalf("version %s: expected %s (%f), got %s (%f)", v.Version, e.escrow, e.share, v.Escrow, v.Share)
+		}
+		totalShare += v.Share
+	}
+	if expectedShare := 0.4; math.Abs(totalShare-expectedShare) > 1e-9 {
+		t.Fatalf("version shares add up to %f, expected %f", totalShare, expectedShare)
+	}
+
+	// Entity 1 is a laggard, as one of its nodes is behind.
+	if s.UpgradedEscrow.String() != "100" || math.Abs(s.UpgradedShare-0.1) > 1e-9 {
+		t.Fatalf("upgraded escrow: expected 100 (0.1), got %s (%f)", s.UpgradedEscrow, s.UpgradedShare)
+	}
+	if s.TotalVotingPower != 40 || s.UpgradedVotingPower != 10 || s.UpgradedVotingShare != 0.25 {
+		t.Fatalf("upgraded voting power: expected 10/40, got %d/%d", s.UpgradedVotingPower, s.TotalVotingPower)
+	}
+
+	for _, e := range r.Entities {
+		if e.Escrow == nil {
+			t.Fatalf("entity %s: escrow not set", e.Address)
+		}
+	}
+}
+
+func TestApplyStakeRounding(t *testing.T) {
+	// 100 split over 3 nodes does not divide evenly.
+	nodes := []*node.Node{
+		testNode(1, 0, "1.0.0"),
+		testNode(1, 1, "1.0.0"),
+		testNode(1, 2, "2.0.0"),
+	}
+	r := testReport(t, nodes, nil, "")
+	r.applyStake(testStakeInfo(nodes, map[byte]uint64{1: 100}, nil))
+
+	var totalShare float64
+	for _, v := range r.Stake.Versions {
+		totalShare += v.Share
+	}
+	if math.Abs(totalShare-1) > 1e-9 {
+		t.Fatalf("version shares add up to %f, expected 1", totalShare)
+	}
+	if e := r.Stake.Versions[0].Escrow.String(); e != "66" {
+		t.Fatalf("escrow of 1.0.0: expected 66 (rounded down), got %s", e)
+	}
+}
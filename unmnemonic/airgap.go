@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/oasisprotocol/tools/unmnemonic/internal/airgap"
+)
+
+var (
+	allowOnline       bool
+	requireLiveMedium bool
+)
+
+func doAirgapCheck() error {
+	fmt.Printf(" Checking if this machine is offline:\n")
+	report, err := airgap.Check()
+	if err != nil {
+		fmt.Printf("  %v\n", err)
+		fmt.Printf("\n")
+		if !allowOnline {
+			return fmt.Errorf("unable to verify that the machine is offline (use --allow-online to override)")
+		}
+		return nil
+	}
+	_, _ = report.WriteTo(os.Stdout)
+	fmt.Printf("\n")
+
+	if report.IsOnline() {
+		fmt.Printf(" WARNING:\n")
+		fmt.Printf("\n")
+		fmt.Printf("  This machine appears to be CONNECTED TO A NETWORK.  Entering your\n")
+		fmt.Printf("  mnemonic into a networked machine can COMPROMISE ALL ACCOUNTS\n")
+		fmt.Printf("  TIED TO THE MNEMONIC.\n")
+		fmt.Printf("\n")
+		if !allowOnline {
+			return fmt.Errorf("refusing to continue while online (use --allow-online to override)")
+		}
+	}
+
+	if requireLiveMedium && !report.IsReadOnlyMedium {
+		return fmt.Errorf("not running from a read-only medium: %s", report.Medium)
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxIndexCount is the maximum number of indexes that can be selected at
+// once, to catch typos like `0-4000000000`.
+const maxIndexCount = 10000
+
+// indexSpec is a selected wallet index, with an optional label.
+type indexSpec struct {
+	index uint32
+	label string
+}
+
+// parseIndexes parses a comma separated list of index selectors, each of
+// which is one of `N`, `A-B` or `A-B/STEP`, optionally followed by
+// `=LABEL`.  Indexes selected by a labeled range are labeled `LABEL-N`.
+func parseIndexes(s string) ([]indexSpec, error) {
+	var (
+		specs []indexSpec
+		seen  = make(map[uint32]bool)
+	)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+
+		var label string
+		if i := strings.IndexByte(item, '='); i >= 0 {
+			item, label = strings.TrimSpace(item[:i]), strings.TrimSpace(item[i+1:])
+			if err := isValidLabel(label); err != nil {
+				return nil, err
+			}
+		}
+
+		start, end, step, err := parseIndexRange(item)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(specs))+(end-start)/step+1 > maxIndexCount {
+			return nil, fmt.Errorf("too many indexes (max %d)", maxIndexCount)
+		}
+
+		for idx := start; idx <= end; idx += step {
+			if seen[uint32(idx)] {
+				return nil, fmt.Errorf("duplicate index: %d", idx)
+			}
+			seen[uint32(idx)] = true
+
+			spec := indexSpec{
+				index: uint32(idx),
+				label: label,
+			}
+			if label != "" && start != end {
+				spec.label = fmt.Sprintf("%s-%d", label, idx)
+			}
+			specs = append(specs, spec)
+		}
+	}
+	return specs, nil
+}
+
+func parseIndexRange(s string) (uint64, uint64, uint64, error) {
+	rangeStr, stepStr := s, ""
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		rangeStr, stepStr = s[:i], s[i+1:]
+	}
+
+	startStr, endStr := rangeStr, rangeStr
+	if i := strings.IndexByte(rangeStr, '-'); i >= 0 {
+		startStr, endStr = rangeStr[:i], rangeStr[i+1:]
+	} else if stepStr != "" {
+		return 0, 0, 0, fmt.Errorf("step without range: '%s'", s)
+	}
+
+	start, err := parseIndex(startStr)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	end, err := parseIndex(endStr)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if end < start {
+		return 0, 0, 0, fmt.Errorf("invalid range (end before start): '%s'", s)
+	}
+
+	step := uint64(1)
+	if stepStr != "" {
+		if step, err = strconv.ParseUint(strings.TrimSpace(stepStr), 10, 32); err != nil || step == 0 {
+			return 0, 0, 0, fmt.Errorf("invalid step: '%s'", stepStr)
+		}
+	}
+
+	return start, end, step, nil
+}
+
+func parseIndex(s string) (uint64, error) {
+	s = strings.TrimSpace(s)
+	i, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid index: '%s'", s)
+	}
+	if i > uint64(maxAccountKeyNumber) {
+		return 0, fmt.Errorf("invalid index (out of range): '%s'", s)
+	}
+	return i, nil
+}
+
+func isValidLabel(label string) error {
+	// Labels end up in file names, so be conservative.
+	if label == "" {
+		return fmt.Errorf("empty label")
+	}
+	for _, c := range label {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.':
+		default:
+			return fmt.Errorf("invalid character in label '%s': '%c'", label, c)
+		}
+	}
+	if label[0] == '.' {
+		return fmt.Errorf("label may not start with '.': '%s'", label)
+	}
+	return nil
+}
+
+func isIndexList(val interface{}) error {
+	_, err := parseIndexes(val.(string))
+	return err
+}
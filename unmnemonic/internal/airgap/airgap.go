@@ -0,0 +1,63 @@
+// Package airgap implements a best-effort check of whether the machine
+// appears to be connected to a network, and whether the binary is being
+// run from a read-only (live) medium.
+package airgap
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Report is the result of an air-gap check.
+type Report struct {
+	// Interfaces is the list of non-loopback network interfaces that
+	// are up.
+	Interfaces []string
+	// Routes is the list of non-loopback routes.
+	Routes []string
+	// Nameservers is the list of configured DNS servers.
+	Nameservers []string
+
+	// Medium is a description of the file system containing the binary.
+	Medium string
+	// IsReadOnlyMedium is true iff the binary is on a read-only file
+	// system.
+	IsReadOnlyMedium bool
+}
+
+// IsOnline returns true iff the machine appears to be connected to a
+// network.
+//
+// Nameservers alone are not considered a sign of connectivity, as the
+// resolver configuration is usually present regardless.
+func (r *Report) IsOnline() bool {
+	return len(r.Interfaces) > 0 || len(r.Routes) > 0
+}
+
+// WriteTo writes a human readable summary of the report.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	writeList := func(descr string, l []string) {
+		if len(l) == 0 {
+			fmt.Fprintf(&b, "  %s: none\n", descr)
+			return
+		}
+		fmt.Fprintf(&b, "  %s:\n", descr)
+		for _, v := range l {
+			fmt.Fprintf(&b, "   - %s\n", v)
+		}
+	}
+	writeList("Network interfaces up", r.Interfaces)
+	writeList("Routes", r.Routes)
+	writeList("DNS servers", r.Nameservers)
+	fmt.Fprintf(&b, "  Running from: %s (read-only: %v)\n", r.Medium, r.IsReadOnlyMedium)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Check inspects the system, and returns a report.
+func Check() (*Report, error) {
+	return check()
+}
@@ -0,0 +1,240 @@
+//go:build linux
+// +build linux
+
+package airgap
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const loopbackInterface = "lo"
+
+// readOnlyFsTypes are file systems that are always read-only, as used by
+// live media.
+var readOnlyFsTypes = map[string]bool{
+	"squashfs": true,
+	"iso9660":  true,
+	"erofs":    true,
+	"udf":      true,
+}
+
+func check() (*Report, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("airgap: failed to locate executable: %w", err)
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return nil, fmt.Errorf("airgap: failed to resolve executable: %w", err)
+	}
+
+	return checkRoot("/", exe)
+}
+
+// checkRoot checks the system with sysfs, procfs and /etc relative to
+// root, for the executable at exe.
+func checkRoot(root, exe string) (*Report, error) {
+	var (
+		r   Report
+		err error
+	)
+	if r.Interfaces, err = getInterfaces(filepath.Join(root, "sys", "class", "net")); err != nil {
+		return nil, err
+	}
+	if r.Routes, err = getRoutes(filepath.Join(root, "proc", "net")); err != nil {
+		return nil, err
+	}
+	if r.Nameservers, err = getNameservers(filepath.Join(root, "etc", "resolv.conf")); err != nil {
+		return nil, err
+	}
+	if r.Medium, r.IsReadOnlyMedium, err = getMedium(filepath.Join(root, "proc", "self", "mountinfo"), exe); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+func getInterfaces(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("airgap: failed to enumerate interfaces: %w", err)
+	}
+
+	var ifaces []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == loopbackInterface {
+			continue
+		}
+
+		operState := readTrimmed(filepath.Join(dir, name, "operstate"))
+		carrier := readTrimmed(filepath.Join(dir, name, "carrier"))
+
+		// Some interfaces (eg: tun devices) always report "unknown",
+		// so fall back to the carrier state.
+		if operState == "up" || (operState == "unknown" && carrier == "1") {
+			ifaces = append(ifaces, fmt.Sprintf("%s (%s)", name, operState))
+		}
+	}
+	return ifaces, nil
+}
+
+func getRoutes(dir string) ([]string, error) {
+	var routes []string
+
+	// Iface Destination Gateway Flags RefCnt Use Metric Mask MTU Window IRTT
+	if err := forEachLine(filepath.Join(dir, "route"), func(fields []string) {
+		if len(fields) < 8 || fields[0] == "Iface" || fields[0] == loopbackInterface {
+			return
+		}
+		dst, mask := parseIPv4Hex(fields[1]), parseIPv4Hex(fields[7])
+		if mask == "0.0.0.0" {
+			routes = append(routes, fmt.Sprintf("default via %s (%s)", parseIPv4Hex(fields[2]), fields[0]))
+			return
+		}
+		routes = append(routes, fmt.Sprintf("%s/%s (%s)", dst, mask, fields[0]))
+	}); err != nil {
+		return nil, err
+	}
+
+	// Destination DestPrefixLen Source SourcePrefixLen NextHop Metric RefCnt Use Flags Iface
+	if err := forEachLine(filepath.Join(dir, "ipv6_route"), func(fields []string) {
+		if len(fields) < 10 || fields[9] == loopbackInterface {
+			return
+		}
+		prefixLen, _ := strconv.ParseUint(fields[1], 16, 8)
+		if prefixLen == 0 {
+			routes = append(routes, fmt.Sprintf("default via %s (%s)", fields[4], fields[9]))
+			return
+		}
+		routes = append(routes, fmt.Sprintf("%s/%d (%s)", fields[0], prefixLen, fields[9]))
+	}); err != nil {
+		return nil, err
+	}
+
+	return routes, nil
+}
+
+func getNameservers(fn string) ([]string, error) {
+	var nameservers []string
+	if err := forEachLine(fn, func(fields []string) {
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			nameservers = append(nameservers, fields[1])
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return nameservers, nil
+}
+
+func getMedium(mountInfo, exe string) (string, bool, error) {
+	var (
+		bestMountPoint string
+		bestDescr      string
+		bestReadOnly   bool
+	)
+
+	// ID ParentID Major:Minor Root MountPoint Options [Optional...] - FsType Source SuperOptions
+	if err := forEachLine(mountInfo, func(fields []string) {
+		sep := -1
+		for i, f := range fields {
+			if f == "-" {
+				sep = i
+				break
+			}
+		}
+		if sep < 6 || len(fields) < sep+4 {
+			return
+		}
+
+		mountPoint := unescapeMountPath(fields[4])
+		if !isPathUnder(exe, mountPoint) || len(mountPoint) < len(bestMountPoint) {
+			return
+		}
+
+		fsType, source := fields[sep+1], fields[sep+2]
+		bestMountPoint = mountPoint
+		bestDescr = fmt.Sprintf("%s on %s (%s)", source, mountPoint, fsType)
+		bestReadOnly = readOnlyFsTypes[fsType] ||
+			hasOption(fields[5], "ro") ||
+			hasOption(fields[sep+3], "ro")
+	}); err != nil {
+		return "", false, err
+	}
+
+	if bestMountPoint == "" {
+		return "unknown", false, nil
+	}
+	return bestDescr, bestReadOnly, nil
+}
+
+func forEachLine(fn string, f func([]string)) error {
+	file, err := os.Open(fn)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return fmt.Errorf("airgap: failed to open '%s': %w", fn, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		f(strings.Fields(scanner.Text()))
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("airgap: failed to read '%s': %w", fn, err)
+	}
+	return nil
+}
+
+func readTrimmed(fn string) string {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func parseIPv4Hex(s string) string {
+	// /proc/net/route uses host byte order, which is little endian on
+	// everything that matters.
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return s
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+func unescapeMountPath(s string) string {
+	// Spaces, tabs, newlines and backslashes are escaped as octal.
+	for _, r := range []struct{ from, to string }{
+		{`\040`, " "},
+		{`\011`, "\t"},
+		{`\012`, "\n"},
+		{`\134`, `\`},
+	} {
+		s = strings.ReplaceAll(s, r.from, r.to)
+	}
+	return s
+}
+
+func isPathUnder(path, dir string) bool {
+	if dir == "/" {
+		return true
+	}
+	return path == dir || strings.HasPrefix(path, dir+"/")
+}
+
+func hasOption(options, opt string) bool {
+	for _, v := range strings.Split(options, ",") {
+		if v == opt {
+			return true
+		}
+	}
+	return false
+}
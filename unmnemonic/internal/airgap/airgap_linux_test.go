@@ -0,0 +1,72 @@
+//go:build linux
+// +build linux
+
+package airgap
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCheckRoot(t *testing.T) {
+	t.Run("Online", func(t *testing.T) {
+		r, err := checkRoot("testdata/online", "/usr/local/bin/unmnemonic")
+		if err != nil {
+			t.Fatalf("checkRoot: %v", err)
+		}
+		if !r.IsOnline() {
+			t.Fatalf("IsOnline: expected true")
+		}
+		if expected := []string{"eth0 (up)"}; !reflect.DeepEqual(r.Interfaces, expected) {
+			t.Errorf("unexpected interfaces: %v", r.Interfaces)
+		}
+		if expected := []string{
+			"default via 192.168.1.1 (eth0)",
+			"192.168.1.0/255.255.255.0 (eth0)",
+		}; !reflect.DeepEqual(r.Routes, expected) {
+			t.Errorf("unexpected routes: %v", r.Routes)
+		}
+		if expected := []string{"192.168.1.1"}; !reflect.DeepEqual(r.Nameservers, expected) {
+			t.Errorf("unexpected nameservers: %v", r.Nameservers)
+		}
+		if r.IsReadOnlyMedium {
+			t.Errorf("IsReadOnlyMedium: expected false (%s)", r.Medium)
+		}
+
+		var buf bytes.Buffer
+		if _, err = r.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo: %v", err)
+		}
+		t.Logf("report:\n%s", buf.String())
+	})
+
+	t.Run("Offline", func(t *testing.T) {
+		r, err := checkRoot("testdata/offline", "/run/live/medium/unmnemonic")
+		if err != nil {
+			t.Fatalf("checkRoot: %v", err)
+		}
+		if r.IsOnline() {
+			t.Fatalf("IsOnline: expected false (%+v)", r)
+		}
+		if !r.IsReadOnlyMedium {
+			t.Errorf("IsReadOnlyMedium: expected true (%s)", r.Medium)
+		}
+
+		r, err = checkRoot("testdata/offline", "/run/live/my tools/unmnemonic")
+		if err != nil {
+			t.Fatalf("checkRoot: %v", err)
+		}
+		if expected := "/dev/loop1 on /run/live/my tools (squashfs)"; r.Medium != expected || !r.IsReadOnlyMedium {
+			t.Errorf("unexpected medium: %s (read-only: %v)", r.Medium, r.IsReadOnlyMedium)
+		}
+
+		r, err = checkRoot("testdata/offline", "/home/user/unmnemonic")
+		if err != nil {
+			t.Fatalf("checkRoot: %v", err)
+		}
+		if r.IsReadOnlyMedium {
+			t.Errorf("IsReadOnlyMedium: expected false (%s)", r.Medium)
+		}
+	})
+}
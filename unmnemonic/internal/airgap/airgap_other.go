@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package airgap
+
+import "fmt"
+
+func check() (*Report, error) {
+	return nil, fmt.Errorf("airgap: checking is not supported on this platform")
+}
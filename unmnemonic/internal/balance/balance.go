@@ -0,0 +1,72 @@
+// Package balance implements minimal staking account balance lookups,
+// either against a local oasis-node, or an offline state dump.
+//
+// Like the rest of unmnemonic, this deliberately avoids importing
+// oasis-core, and only speaks the tiny subset of the node's gRPC
+// interface that is required to look up an account.  The gRPC client is
+// only built with the `grpc` build tag.
+package balance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// Account is the subset of a staking account relevant for recovery.
+//
+// All balances are in base units.
+type Account struct {
+	General   *big.Int
+	Escrow    *big.Int
+	Debonding *big.Int
+	Nonce     uint64
+}
+
+// IsEmpty returns true iff the account has never been used, as in it has
+// no balance of any kind, and has never sent a transaction.
+func (a *Account) IsEmpty() bool {
+	return a.General.Sign() == 0 && a.Escrow.Sign() == 0 && a.Debonding.Sign() == 0 && a.Nonce == 0
+}
+
+// Source is an account balance source.
+type Source interface {
+	// GetAccount returns the account associated with a bech32 encoded
+	// staking address.  Accounts that do not exist are returned as
+	// empty accounts.
+	GetAccount(ctx context.Context, addr string) (*Account, error)
+
+	// Close releases all resources associated with the source.
+	Close() error
+}
+
+// NewSource returns a balance source for the provided location, which is
+// either a node gRPC endpoint (a UNIX socket, `unix:<path>`, or
+// `<host>:<port>`), or the path to a JSON state dump.
+func NewSource(location string) (Source, error) {
+	if strings.HasPrefix(location, "unix:") {
+		return NewGRPCSource(location)
+	}
+
+	fi, err := os.Stat(location)
+	switch {
+	case err == nil && fi.Mode()&os.ModeSocket != 0:
+		return NewGRPCSource("unix:" + location)
+	case err == nil:
+		return NewDumpSource(location)
+	case strings.Contains(location, ":"):
+		return NewGRPCSource(location)
+	default:
+		return nil, fmt.Errorf("balance: failed to stat source: %w", err)
+	}
+}
+
+func newEmptyAccount() *Account {
+	return &Account{
+		General:   new(big.Int),
+		Escrow:    new(big.Int),
+		Debonding: new(big.Int),
+	}
+}
@@ -0,0 +1,53 @@
+package balance
+
+import (
+	"context"
+	"testing"
+)
+
+const (
+	testAddrUsed  = "oasis1qryqqccycvckcxp453tflalujvlf78xymcdqw4vz"
+	testAddrEmpty = "oasis1qzzytegg6jc7hxu6y8feuzkgmr75ms7hc54mz85p"
+)
+
+func TestDumpSource(t *testing.T) {
+	src, err := NewSource("../testdata/state_dump.json")
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	defer src.Close()
+
+	testSource(t, src)
+}
+
+func testSource(t *testing.T, src Source) {
+	ctx := context.Background()
+
+	acc, err := src.GetAccount(ctx, testAddrUsed)
+	if err != nil {
+		t.Fatalf("GetAccount(used): %v", err)
+	}
+	if acc.IsEmpty() {
+		t.Fatalf("GetAccount(used): account is empty")
+	}
+	if s := acc.General.String(); s != "1000000000" {
+		t.Errorf("GetAccount(used): unexpected general balance: %s", s)
+	}
+	if s := acc.Escrow.String(); s != "2000000000" {
+		t.Errorf("GetAccount(used): unexpected escrow balance: %s", s)
+	}
+	if s := acc.Debonding.String(); s != "3" {
+		t.Errorf("GetAccount(used): unexpected debonding balance: %s", s)
+	}
+	if acc.Nonce != 7 {
+		t.Errorf("GetAccount(used): unexpected nonce: %d", acc.Nonce)
+	}
+
+	acc, err = src.GetAccount(ctx, testAddrEmpty)
+	if err != nil {
+		t.Fatalf("GetAccount(empty): %v", err)
+	}
+	if !acc.IsEmpty() {
+		t.Fatalf("GetAccount(empty): account is not empty")
+	}
+}
@@ -0,0 +1,84 @@
+package balance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+type dumpSource struct {
+	ledger map[string]*dumpAccount
+}
+
+// The state dump is a genesis document (eg: as produced by
+// `oasis-node debug dumpdb`), of which only the staking ledger is
+// interesting.
+type dumpDocument struct {
+	Staking struct {
+		Ledger map[string]*dumpAccount `json:"ledger"`
+	} `json:"staking"`
+}
+
+type dumpAccount struct {
+	General struct {
+		Balance dumpQuantity `json:"balance"`
+		Nonce   uint64       `json:"nonce"`
+	} `json:"general"`
+	Escrow struct {
+		Active struct {
+			Balance dumpQuantity `json:"balance"`
+		} `json:"active"`
+		Debonding struct {
+			Balance dumpQuantity `json:"balance"`
+		} `json:"debonding"`
+	} `json:"escrow"`
+}
+
+// dumpQuantity is a quantity, serialized as a decimal string.
+type dumpQuantity struct {
+	v big.Int
+}
+
+func (q *dumpQuantity) UnmarshalText(text []byte) error {
+	if _, ok := q.v.SetString(string(text), 10); !ok || q.v.Sign() < 0 {
+		return fmt.Errorf("balance: malformed quantity: '%s'", text)
+	}
+	return nil
+}
+
+func (s *dumpSource) GetAccount(ctx context.Context, addr string) (*Account, error) {
+	acc := newEmptyAccount()
+	if dAcc := s.ledger[addr]; dAcc != nil {
+		acc.General.Set(&dAcc.General.Balance.v)
+		acc.Escrow.Set(&dAcc.Escrow.Active.Balance.v)
+		acc.Debonding.Set(&dAcc.Escrow.Debonding.Balance.v)
+		acc.Nonce = dAcc.General.Nonce
+	}
+	return acc, nil
+}
+
+func (s *dumpSource) Close() error {
+	return nil
+}
+
+// NewDumpSource returns a balance source backed by a JSON state dump.
+func NewDumpSource(fn string) (Source, error) {
+	b, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("balance: failed to read state dump: %w", err)
+	}
+
+	var doc dumpDocument
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("balance: failed to parse state dump: %w", err)
+	}
+	if doc.Staking.Ledger == nil {
+		return nil, fmt.Errorf("balance: state dump has no staking ledger")
+	}
+
+	return &dumpSource{
+		ledger: doc.Staking.Ledger,
+	}, nil
+}
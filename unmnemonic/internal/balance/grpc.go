@@ -0,0 +1,113 @@
+//go:build grpc
+// +build grpc
+
+package balance
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/fxamacker/cbor/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/address"
+)
+
+const (
+	cborCodecName = "cbor"
+
+	methodStakingAccount = "/oasis-core.Staking/Account"
+
+	heightLatest = int64(0)
+)
+
+// cborCodec is the oasis-node gRPC CBOR codec.
+type cborCodec struct{}
+
+func (c *cborCodec) Marshal(v interface{}) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+func (c *cborCodec) Unmarshal(data []byte, v interface{}) error {
+	return cbor.Unmarshal(data, v)
+}
+
+func (c *cborCodec) Name() string {
+	return cborCodecName
+}
+
+type ownerQuery struct {
+	Height int64  `cbor:"height"`
+	Owner  []byte `cbor:"owner"`
+}
+
+// grpcQuantity is a quantity, serialized as a big-endian byte string.
+type grpcQuantity []byte
+
+func (q grpcQuantity) toBigInt() *big.Int {
+	return new(big.Int).SetBytes(q)
+}
+
+type grpcAccount struct {
+	General struct {
+		Balance grpcQuantity `cbor:"balance"`
+		Nonce   uint64       `cbor:"nonce"`
+	} `cbor:"general"`
+	Escrow struct {
+		Active struct {
+			Balance grpcQuantity `cbor:"balance"`
+		} `cbor:"active"`
+		Debonding struct {
+			Balance grpcQuantity `cbor:"balance"`
+		} `cbor:"debonding"`
+	} `cbor:"escrow"`
+}
+
+type grpcSource struct {
+	conn *grpc.ClientConn
+}
+
+func (s *grpcSource) GetAccount(ctx context.Context, addr string) (*Account, error) {
+	rawAddr, err := address.Decode(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &ownerQuery{
+		Height: heightLatest,
+		Owner:  rawAddr,
+	}
+	var rsp grpcAccount
+	if err = s.conn.Invoke(ctx, methodStakingAccount, query, &rsp); err != nil {
+		return nil, fmt.Errorf("balance: failed to query account '%s': %w", addr, err)
+	}
+
+	return &Account{
+		General:   rsp.General.Balance.toBigInt(),
+		Escrow:    rsp.Escrow.Active.Balance.toBigInt(),
+		Debonding: rsp.Escrow.Debonding.Balance.toBigInt(),
+		Nonce:     rsp.General.Nonce,
+	}, nil
+}
+
+func (s *grpcSource) Close() error {
+	return s.conn.Close()
+}
+
+// NewGRPCSource returns a balance source backed by a node's gRPC endpoint.
+func NewGRPCSource(target string) (Source, error) {
+	conn, err := grpc.Dial(
+		target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(&cborCodec{})),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("balance: failed to dial node: %w", err)
+	}
+
+	return &grpcSource{
+		conn: conn,
+	}, nil
+}
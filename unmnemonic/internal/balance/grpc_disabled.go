@@ -0,0 +1,12 @@
+//go:build !grpc
+// +build !grpc
+
+package balance
+
+import "fmt"
+
+// NewGRPCSource returns an error, as the gRPC client is only built with
+// the `grpc` build tag.
+func NewGRPCSource(target string) (Source, error) {
+	return nil, fmt.Errorf("balance: node gRPC lookups are not supported by this build (rebuild with `-tags grpc`), use a state dump instead")
+}
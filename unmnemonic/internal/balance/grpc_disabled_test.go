@@ -0,0 +1,12 @@
+//go:build !grpc
+// +build !grpc
+
+package balance
+
+import "testing"
+
+func TestGRPCSourceDisabled(t *testing.T) {
+	if _, err := NewSource("unix:/nonexistent/internal.sock"); err == nil {
+		t.Fatalf("NewSource: failed to reject gRPC endpoint")
+	}
+}
@@ -0,0 +1,58 @@
+//go:build grpc
+// +build grpc
+
+package balance
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/address"
+)
+
+func TestGRPCSource(t *testing.T) {
+	encoding.RegisterCodec(&cborCodec{})
+
+	rawAddrUsed, err := address.Decode(testAddrUsed)
+	if err != nil {
+		t.Fatalf("address.Decode: %v", err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+		method, _ := grpc.MethodFromServerStream(stream)
+		if method != methodStakingAccount {
+			t.Errorf("unexpected method: %s", method)
+		}
+
+		var query ownerQuery
+		if err := stream.RecvMsg(&query); err != nil {
+			return err
+		}
+		var rsp grpcAccount
+		if bytes.Equal(query.Owner, rawAddrUsed) {
+			rsp.General.Balance = grpcQuantity{0x3b, 0x9a, 0xca, 0x00}
+			rsp.General.Nonce = 7
+			rsp.Escrow.Active.Balance = grpcQuantity{0x77, 0x35, 0x94, 0x00}
+			rsp.Escrow.Debonding.Balance = grpcQuantity{0x03}
+		}
+		return stream.SendMsg(&rsp)
+	}))
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	src, err := NewSource(l.Addr().String())
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+	defer src.Close()
+
+	testSource(t, src)
+}
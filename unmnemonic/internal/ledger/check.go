@@ -0,0 +1,68 @@
+package ledger
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/wallet"
+)
+
+// CheckResult is the result of checking a single get address exchange.
+type CheckResult struct {
+	// Path is the derivation path.
+	Path string
+
+	// DevicePublicKey and DeviceAddress are the values returned by the
+	// device.
+	DevicePublicKey ed25519.PublicKey
+	DeviceAddress   string
+
+	// PublicKey and Address are the values derived by unmnemonic.
+	PublicKey ed25519.PublicKey
+	Address   string
+}
+
+// IsMatch returns true iff the derived public key and address match the
+// ones returned by the device.
+func (r *CheckResult) IsMatch() bool {
+	return bytes.Equal(r.DevicePublicKey, r.PublicKey) && r.DeviceAddress == r.Address
+}
+
+// Check requests the address for the path of each recorded get address
+// command over the transport, and compares the response with the Ledger
+// scheme derivation from the seed of the device's mnemonic.
+func Check(t Transport, exchanges []*Exchange, seed []byte) ([]*CheckResult, error) {
+	d, err := wallet.New(wallet.SchemeLedger)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*CheckResult
+	for _, e := range exchanges {
+		path, ok := decodeGetAddress(e.Command)
+		if !ok {
+			continue
+		}
+		r := &CheckResult{
+			Path: FormatPath(path),
+		}
+
+		if r.DevicePublicKey, r.DeviceAddress, err = GetAddress(t, path); err != nil {
+			return nil, fmt.Errorf("ledger: failed to get address for '%s': %w", r.Path, err)
+		}
+		key, err := d.Derive(seed, r.Path)
+		if err != nil {
+			return nil, fmt.Errorf("ledger: failed to derive key for '%s': %w", r.Path, err)
+		}
+		r.PublicKey, r.Address = key.PublicKey, key.Address
+
+		results = append(results, r)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("ledger: transcript has no get address exchanges")
+	}
+
+	return results, nil
+}
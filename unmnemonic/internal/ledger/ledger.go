@@ -0,0 +1,115 @@
+// Package ledger implements enough of the Oasis Ledger app APDU protocol
+// to cross-check the Ledger derivation against (recorded) device responses.
+package ledger
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+)
+
+const (
+	claOasis          = 0x05
+	insGetAddrEd25519 = 0x01
+	p1NoConfirmation  = 0x00
+
+	headerSize     = 5
+	pathComponents = 5
+	statusSize     = 2
+	statusOK       = 0x9000
+)
+
+// Transport is an APDU transport to a Ledger device.
+type Transport interface {
+	// Exchange sends a command APDU, and returns the response APDU
+	// (including the trailing status word).
+	Exchange(command []byte) ([]byte, error)
+}
+
+// StatusError is the error returned when the device responds with a
+// status word other than success.
+type StatusError uint16
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("ledger: device returned status 0x%04x", uint16(e))
+}
+
+// GetAddress requests the Ed25519 public key and the address at the
+// provided BIP-44 path from the Oasis app, without on-device confirmation.
+func GetAddress(t Transport, path []uint32) (ed25519.PublicKey, string, error) {
+	command, err := encodeGetAddress(path)
+	if err != nil {
+		return nil, "", err
+	}
+	response, err := t.Exchange(command)
+	if err != nil {
+		return nil, "", fmt.Errorf("ledger: exchange failed: %w", err)
+	}
+
+	l := len(response)
+	if l < statusSize {
+		return nil, "", fmt.Errorf("ledger: truncated response")
+	}
+	if sw := binary.BigEndian.Uint16(response[l-statusSize:]); sw != statusOK {
+		return nil, "", StatusError(sw)
+	}
+	response = response[:l-statusSize]
+	if len(response) <= ed25519.PublicKeySize {
+		return nil, "", fmt.Errorf("ledger: malformed get address response")
+	}
+
+	pk := ed25519.PublicKey(append([]byte{}, response[:ed25519.PublicKeySize]...))
+	return pk, string(response[ed25519.PublicKeySize:]), nil
+}
+
+// FormatPath formats a BIP-44 path in the `m/44'/474'/...` notation.
+func FormatPath(path []uint32) string {
+	var b strings.Builder
+	b.WriteString("m")
+	for _, v := range path {
+		if v >= bip32.HardenedIndexOffset {
+			fmt.Fprintf(&b, "/%d'", v-bip32.HardenedIndexOffset)
+		} else {
+			fmt.Fprintf(&b, "/%d", v)
+		}
+	}
+	return b.String()
+}
+
+func encodeGetAddress(path []uint32) ([]byte, error) {
+	if len(path) != pathComponents {
+		return nil, fmt.Errorf("ledger: invalid path length: %d", len(path))
+	}
+
+	command := make([]byte, headerSize+4*pathComponents)
+	command[0] = claOasis
+	command[1] = insGetAddrEd25519
+	command[2] = p1NoConfirmation
+	command[3] = 0
+	command[4] = 4 * pathComponents
+	for i, v := range path {
+		binary.LittleEndian.PutUint32(command[headerSize+4*i:], v)
+	}
+	return command, nil
+}
+
+// decodeGetAddress returns the path of a get address command, or false
+// if the command is not a get address command.
+func decodeGetAddress(command []byte) ([]uint32, bool) {
+	if len(command) != headerSize+4*pathComponents ||
+		command[0] != claOasis ||
+		command[1] != insGetAddrEd25519 ||
+		int(command[4]) != 4*pathComponents {
+		return nil, false
+	}
+
+	path := make([]uint32, 0, pathComponents)
+	for data := command[headerSize:]; len(data) > 0; data = data[4:] {
+		path = append(path, binary.LittleEndian.Uint32(data))
+	}
+	return path, true
+}
@@ -0,0 +1,103 @@
+package ledger
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+)
+
+const testMnemonic = "equip will roof matter pink blind book anxiety banner elbow sun young"
+
+// loadTestTranscript loads a SYNTHETIC transcript, constructed from the
+// Ledger reference derivation vector and not recorded from a device.  The
+// tests using it exercise the transcript parsing, the replay, and the
+// comparison logic, and are not a compatibility check against the Ledger
+// app.
+func loadTestTranscript(t *testing.T) []*Exchange {
+	f, err := os.Open("testdata/synthetic.apdu")
+	if err != nil {
+		t.Fatalf("os.Open: %v", err)
+	}
+	defer f.Close()
+
+	exchanges, err := ParseTranscript(f)
+	if err != nil {
+		t.Fatalf("ParseTranscript: %v", err)
+	}
+	if len(exchanges) != 2 {
+		t.Fatalf("unexpected number of exchanges: %d", len(exchanges))
+	}
+	return exchanges
+}
+
+func TestCheck(t *testing.T) {
+	exchanges := loadTestTranscript(t)
+	seed := bip39.MnemonicToSeed(nil, []byte(testMnemonic))
+
+	results, err := Check(NewReplayTransport(exchanges), exchanges, seed)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("unexpected number of results: %d", len(results))
+	}
+	r := results[0]
+	if r.Path != "m/44'/474'/5'/0'/3'" {
+		t.Errorf("unexpected path: %s", r.Path)
+	}
+	if r.DeviceAddress != "oasis1qphdkldpttpsj2j3l9sde9h26cwpfwqwwuhvruyu" {
+		t.Errorf("unexpected device address: %s", r.DeviceAddress)
+	}
+	if !r.IsMatch() {
+		t.Fatalf("derivation does not match the device: %+v", r)
+	}
+
+	// A different mnemonic must not match.
+	seed = bip39.MnemonicToSeed([]byte("not the device passphrase"), []byte(testMnemonic))
+	if results, err = Check(NewReplayTransport(exchanges), exchanges, seed); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if results[0].IsMatch() {
+		t.Fatalf("derivation from a different seed matches the device")
+	}
+}
+
+func TestGetAddressError(t *testing.T) {
+	path := []uint32{0x8000002c, 0x800001da, 0x80000000, 0x80000000, 0x80000000}
+	command, err := encodeGetAddress(path)
+	if err != nil {
+		t.Fatalf("encodeGetAddress: %v", err)
+	}
+
+	// Rejected by the device (eg: app not open).
+	transport := NewReplayTransport([]*Exchange{
+		{Command: command, Response: []byte{0x6e, 0x01}},
+	})
+	_, _, err = GetAddress(transport, path)
+	var statusErr StatusError
+	if !errors.As(err, &statusErr) || statusErr != 0x6e01 {
+		t.Fatalf("GetAddress: unexpected error: %v", err)
+	}
+
+	// Each recorded response is only replayed once.
+	if _, _, err = GetAddress(transport, path); err == nil {
+		t.Fatalf("GetAddress: response replayed twice")
+	}
+}
+
+func TestParseTranscriptMalformed(t *testing.T) {
+	for _, s := range []string{
+		"=> 0500000000\n",
+		"<= 9000\n",
+		"=> 0500000000\n=> 0500000000\n",
+		"=> 05zz\n<= 9000\n",
+		"0500000000\n",
+	} {
+		if _, err := ParseTranscript(strings.NewReader(s)); err == nil {
+			t.Errorf("ParseTranscript: accepted malformed transcript: %q", s)
+		}
+	}
+}
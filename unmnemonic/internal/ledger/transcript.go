@@ -0,0 +1,107 @@
+package ledger
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	commandPrefix  = "=>"
+	responsePrefix = "<="
+)
+
+// Exchange is a recorded command/response APDU pair.
+type Exchange struct {
+	Command  []byte
+	Response []byte
+}
+
+// ParseTranscript parses a recorded APDU transcript, in the format used
+// by the Ledger JS record/replay transports, where each command is on a
+// line starting with `=>`, followed by the response on a line starting
+// with `<=`, both hex encoded.  Blank lines and lines starting with `#`
+// are ignored.
+func ParseTranscript(r io.Reader) ([]*Exchange, error) {
+	var (
+		exchanges []*Exchange
+		pending   *Exchange
+		lineNr    int
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNr++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var prefix string
+		switch {
+		case strings.HasPrefix(line, commandPrefix):
+			prefix = commandPrefix
+		case strings.HasPrefix(line, responsePrefix):
+			prefix = responsePrefix
+		default:
+			return nil, fmt.Errorf("ledger: transcript line %d: unknown direction", lineNr)
+		}
+		b, err := hex.DecodeString(strings.TrimSpace(strings.TrimPrefix(line, prefix)))
+		if err != nil {
+			return nil, fmt.Errorf("ledger: transcript line %d: malformed hex: %w", lineNr, err)
+		}
+
+		switch {
+		case prefix == commandPrefix && pending == nil:
+			pending = &Exchange{
+				Command: b,
+			}
+		case prefix == responsePrefix && pending != nil:
+			pending.Response = b
+			exchanges = append(exchanges, pending)
+			pending = nil
+		default:
+			return nil, fmt.Errorf("ledger: transcript line %d: unpaired APDU", lineNr)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ledger: failed to read transcript: %w", err)
+	}
+	if pending != nil {
+		return nil, fmt.Errorf("ledger: transcript ends with a command without a response")
+	}
+
+	return exchanges, nil
+}
+
+// ReplayTransport is a Transport that responds to commands with the
+// responses recorded in a transcript, in place of a device.
+type ReplayTransport struct {
+	responses map[string][][]byte
+}
+
+// Exchange returns the next recorded response to the command.
+func (t *ReplayTransport) Exchange(command []byte) ([]byte, error) {
+	k := string(command)
+	responses := t.responses[k]
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("ledger: no recorded response for command: %x", command)
+	}
+	t.responses[k] = responses[1:]
+	return responses[0], nil
+}
+
+// NewReplayTransport returns a new ReplayTransport for the recorded
+// exchanges.  Each recorded response is returned once, in the order
+// recorded for identical commands.
+func NewReplayTransport(exchanges []*Exchange) *ReplayTransport {
+	t := &ReplayTransport{
+		responses: make(map[string][][]byte),
+	}
+	for _, e := range exchanges {
+		k := string(e.Command)
+		t.responses[k] = append(t.responses[k], e.Response)
+	}
+	return t
+}
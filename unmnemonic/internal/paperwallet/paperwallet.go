@@ -0,0 +1,148 @@
+// Package paperwallet implements rendering derived wallets as QR codes,
+// both to the terminal and as a printable SVG paper wallet sheet.
+package paperwallet
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// Sheet geometry, in millimeters (A4 portrait).
+	sheetWidth   = 210
+	sheetMargin  = 15
+	rowQRSize    = 45
+	rowHeight    = rowQRSize + 10
+	headerHeight = 20
+)
+
+// Entry is a single wallet on a paper wallet sheet.
+type Entry struct {
+	// Index is the wallet index.
+	Index uint32
+	// Scheme is the derivation scheme used to derive the wallet.
+	Scheme string
+	// Address is the bech32 encoded staking address.
+	Address string
+	// EncryptedKey is the optional ASCII-armored age encrypted private key.
+	EncryptedKey string
+}
+
+// EncryptKey encrypts a (PEM encoded) private key with a passphrase,
+// returning the ASCII-armored age ciphertext, which can be decrypted
+// with `age --decrypt`.
+func EncryptKey(key []byte, passphrase string) (string, error) {
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return "", fmt.Errorf("paperwallet: failed to create recipient: %w", err)
+	}
+
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, recipient)
+	if err != nil {
+		return "", fmt.Errorf("paperwallet: failed to initialize encryption: %w", err)
+	}
+	if _, err = w.Write(key); err != nil {
+		return "", fmt.Errorf("paperwallet: failed to encrypt key: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return "", fmt.Errorf("paperwallet: failed to finalize encryption: %w", err)
+	}
+	if err = aw.Close(); err != nil {
+		return "", fmt.Errorf("paperwallet: failed to finalize armor: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// RenderTerminal writes the entry's address as a QR code suitable for
+// display on a terminal.  Private keys are never rendered to the terminal.
+func RenderTerminal(w io.Writer, e *Entry) error {
+	qr, err := qrcode.New(e.Address, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("paperwallet: failed to encode address: %w", err)
+	}
+
+	fmt.Fprintf(w, " Index[%d] (%s): %s\n", e.Index, e.Scheme, e.Address)
+	fmt.Fprint(w, qr.ToSmallString(false))
+
+	return nil
+}
+
+// WriteSVG writes a printable paper wallet sheet containing the entries.
+func WriteSVG(w io.Writer, title string, entries []*Entry) error {
+	height := 2*sheetMargin + headerHeight + len(entries)*rowHeight
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(
+		&b,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%dmm" height="%dmm" viewBox="0 0 %d %d" font-family="monospace">`+"\n",
+		sheetWidth, height, sheetWidth, height,
+	)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+	fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="6">%s</text>`+"\n", sheetMargin, sheetMargin+6, html.EscapeString(title))
+
+	for i, e := range entries {
+		y := sheetMargin + headerHeight + i*rowHeight
+
+		if err := writeSVGQR(&b, e.Address, sheetMargin, y, rowQRSize); err != nil {
+			return err
+		}
+
+		textX := sheetMargin + rowQRSize + 5
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="4">Index: %d</text>`+"\n", textX, y+6, e.Index)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="4">Scheme: %s</text>`+"\n", textX, y+12, html.EscapeString(e.Scheme))
+		fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="3">%s</text>`+"\n", textX, y+18, html.EscapeString(e.Address))
+
+		if e.EncryptedKey != "" {
+			fmt.Fprintf(&b, `<text x="%d" y="%d" font-size="3">Encrypted key (age) &#8594;</text>`+"\n", textX, y+30)
+			if err := writeSVGQR(&b, e.EncryptedKey, sheetWidth-sheetMargin-rowQRSize, y, rowQRSize); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(&b, "</svg>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeSVGQR(b *strings.Builder, content string, x, y, size int) error {
+	qr, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("paperwallet: failed to encode QR code: %w", err)
+	}
+	bitmap := qr.Bitmap()
+
+	// Each module is scaled to fit the requested size, with the entire
+	// code drawn as a single path to keep the output reasonably small.
+	modSize := float64(size) / float64(len(bitmap))
+	fmt.Fprintf(b, `<path fill="black" d="`)
+	for row, bits := range bitmap {
+		for col, isSet := range bits {
+			if !isSet {
+				continue
+			}
+			fmt.Fprintf(
+				b,
+				"M%.3f %.3fh%.3fv%.3fh-%.3fz",
+				float64(x)+float64(col)*modSize,
+				float64(y)+float64(row)*modSize,
+				modSize, modSize, modSize,
+			)
+		}
+	}
+	fmt.Fprintf(b, `"/>`+"\n")
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package paperwallet
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const testAddr = "oasis1qryqqccycvckcxp453tflalujvlf78xymcdqw4vz"
+
+func TestEncryptKey(t *testing.T) {
+	const passphrase = "correct horse battery staple"
+	key := []byte("-----BEGIN ED25519 PRIVATE KEY-----\nnot-a-real-key\n-----END ED25519 PRIVATE KEY-----\n")
+
+	ciphertext, err := EncryptKey(key, passphrase)
+	if err != nil {
+		t.Fatalf("EncryptKey: %v", err)
+	}
+	if !strings.HasPrefix(ciphertext, armor.Header) {
+		t.Fatalf("EncryptKey: output is not armored")
+	}
+
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		t.Fatalf("age.NewScryptIdentity: %v", err)
+	}
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(ciphertext)), identity)
+	if err != nil {
+		t.Fatalf("age.Decrypt: %v", err)
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("failed to read plaintext: %v", err)
+	}
+	if !bytes.Equal(key, plaintext) {
+		t.Fatalf("plaintext mismatch: expected '%s', got '%s'", key, plaintext)
+	}
+}
+
+func TestRender(t *testing.T) {
+	entries := []*Entry{
+		{
+			Index:   3,
+			Scheme:  "ADR-0008",
+			Address: testAddr,
+		},
+		{
+			Index:        4,
+			Scheme:       "ADR-0008",
+			Address:      testAddr,
+			EncryptedKey: armor.Header + "\nnot-a-real-ciphertext\n" + armor.Footer + "\n",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := RenderTerminal(&buf, entries[0]); err != nil {
+		t.Fatalf("RenderTerminal: %v", err)
+	}
+	if !strings.Contains(buf.String(), testAddr) {
+		t.Fatalf("RenderTerminal: address missing from output")
+	}
+
+	buf.Reset()
+	if err := WriteSVG(&buf, "test <sheet>", entries); err != nil {
+		t.Fatalf("WriteSVG: %v", err)
+	}
+	svg := buf.String()
+	for _, s := range []string{testAddr, "Index: 3", "Index: 4", "Scheme: ADR-0008", "test &lt;sheet&gt;"} {
+		if !strings.Contains(svg, s) {
+			t.Errorf("WriteSVG: '%s' missing from output", s)
+		}
+	}
+	if n := strings.Count(svg, "<path"); n != 3 {
+		t.Errorf("WriteSVG: expected 3 QR codes, got %d", n)
+	}
+}
@@ -0,0 +1,149 @@
+// Package proof implements oasis-core compatible domain separated
+// signatures, and signed "proof of control" statements.
+package proof
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/address"
+)
+
+const (
+	// Context is the signature context used for proof of control
+	// statements.
+	Context = "oasis-unmnemonic/proof-of-control: v0"
+
+	chainContextSeparator = " for chain "
+	chainContextMaxSize   = 64
+)
+
+// verifyOptions are the Ed25519 verification options used by oasis-core.
+var verifyOptions = &ed25519.Options{
+	Verify: &ed25519.VerifyOptions{
+		AllowSmallOrderA:   false,
+		AllowSmallOrderR:   false,
+		AllowNonCanonicalA: true,
+		AllowNonCanonicalR: true,
+	},
+}
+
+// PrepareMessage returns the message that is actually signed, for a
+// message signed with the provided context, following oasis-core:
+// SHA512/256(context || message), where the context is suffixed with
+// " for chain " and the chain context if the chain context is set.
+func PrepareMessage(context, chainContext string, message []byte) ([]byte, error) {
+	if l := len(context); l == 0 || l > ed25519.ContextMaxSize {
+		return nil, fmt.Errorf("proof: malformed context")
+	}
+	if len(chainContext) > chainContextMaxSize {
+		return nil, fmt.Errorf("proof: malformed chain context")
+	}
+	if chainContext != "" {
+		context = context + chainContextSeparator + chainContext
+	}
+
+	h := sha512.New512_256()
+	_, _ = h.Write([]byte(context))
+	_, _ = h.Write(message)
+	return h.Sum(nil), nil
+}
+
+// Sign signs a message with the provided context and chain context.
+func Sign(signer crypto.Signer, context, chainContext string, message []byte) ([]byte, error) {
+	data, err := PrepareMessage(context, chainContext, message)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := signer.Sign(nil, data, crypto.Hash(0))
+	if err != nil {
+		return nil, fmt.Errorf("proof: failed to sign: %w", err)
+	}
+	return sig, nil
+}
+
+// Verify verifies a signature over a message with the provided context
+// and chain context, the same way oasis-core does.
+func Verify(publicKey ed25519.PublicKey, context, chainContext string, message, sig []byte) bool {
+	if len(publicKey) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+	data, err := PrepareMessage(context, chainContext, message)
+	if err != nil {
+		return false
+	}
+	return ed25519.VerifyWithOptions(publicKey, data, sig, verifyOptions)
+}
+
+// Statement is a detached proof of control statement.
+type Statement struct {
+	Address      string `json:"address"`
+	PublicKey    []byte `json:"public_key"`
+	Context      string `json:"context"`
+	ChainContext string `json:"chain_context,omitempty"`
+	Message      string `json:"message"`
+	Signature    []byte `json:"signature"`
+}
+
+// NewStatement signs a proof of control statement with the provided key,
+// and verifies the signature.
+func NewStatement(signer crypto.Signer, chainContext string, now time.Time) (*Statement, error) {
+	publicKey, ok := signer.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("proof: unsupported public key type: %T", signer.Public())
+	}
+	addr, err := address.FromPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("proof: failed to derive address: %w", err)
+	}
+
+	s := &Statement{
+		Address:      addr,
+		PublicKey:    append([]byte{}, publicKey...),
+		Context:      Context,
+		ChainContext: chainContext,
+		Message:      fmt.Sprintf("I control the Oasis account %s (%s).", addr, now.UTC().Format(time.RFC3339)),
+	}
+	if s.Signature, err = Sign(signer, s.Context, s.ChainContext, []byte(s.Message)); err != nil {
+		return nil, err
+	}
+	if err = s.Verify(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Verify verifies the statement's signature, and that the address
+// corresponds to the public key.
+func (s *Statement) Verify() error {
+	publicKey := ed25519.PublicKey(s.PublicKey)
+	if len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("proof: malformed public key")
+	}
+	addr, err := address.FromPublicKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("proof: failed to derive address: %w", err)
+	}
+	if addr != s.Address {
+		return fmt.Errorf("proof: address does not match the public key")
+	}
+	if !Verify(publicKey, s.Context, s.ChainContext, []byte(s.Message), s.Signature) {
+		return fmt.Errorf("proof: invalid signature")
+	}
+	return nil
+}
+
+// MarshalIndent returns the indented JSON encoding of the statement.
+func (s *Statement) MarshalIndent() ([]byte, error) {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("proof: failed to serialize statement: %w", err)
+	}
+	return append(b, '\n'), nil
+}
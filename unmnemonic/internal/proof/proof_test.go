@@ -0,0 +1,101 @@
+package proof
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha512"
+	"testing"
+	"time"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+)
+
+func testSigners(t *testing.T) map[string]crypto.Signer {
+	seed := bytes.Repeat([]byte{0x42}, 64)
+	root, err := bip32.NewRoot(seed)
+	if err != nil {
+		t.Fatalf("bip32.NewRoot: %v", err)
+	}
+	child, err := root.DerivePath("m/44'/474'/0'/0'/0'")
+	if err != nil {
+		t.Fatalf("DerivePath: %v", err)
+	}
+
+	return map[string]crypto.Signer{
+		"Ed25519":       ed25519.NewKeyFromSeed(seed[:ed25519.SeedSize]),
+		"BIP32-Ed25519": child.GetExtendedPrivateKey(),
+	}
+}
+
+func TestPrepareMessage(t *testing.T) {
+	h := sha512.New512_256()
+	_, _ = h.Write([]byte("test context for chain abcd"))
+	_, _ = h.Write([]byte("message"))
+	expected := h.Sum(nil)
+
+	data, err := PrepareMessage("test context", "abcd", []byte("message"))
+	if err != nil {
+		t.Fatalf("PrepareMessage: %v", err)
+	}
+	if !bytes.Equal(data, expected) {
+		t.Fatalf("unexpected prepared message: %x", data)
+	}
+
+	if _, err = PrepareMessage("", "", nil); err == nil {
+		t.Fatalf("PrepareMessage: accepted empty context")
+	}
+	if _, err = PrepareMessage("test context", string(make([]byte, 65)), nil); err == nil {
+		t.Fatalf("PrepareMessage: accepted overly long chain context")
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	for name, signer := range testSigners(t) {
+		t.Run(name, func(t *testing.T) {
+			pk := signer.Public().(ed25519.PublicKey)
+			msg := []byte("message")
+
+			sig, err := Sign(signer, "test context", "abcd", msg)
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			if !Verify(pk, "test context", "abcd", msg, sig) {
+				t.Fatalf("Verify: valid signature rejected")
+			}
+			if Verify(pk, "test context", "other chain", msg, sig) {
+				t.Fatalf("Verify: accepted signature for a different chain")
+			}
+			if Verify(pk, "other context", "abcd", msg, sig) {
+				t.Fatalf("Verify: accepted signature for a different context")
+			}
+		})
+	}
+}
+
+func TestStatement(t *testing.T) {
+	for name, signer := range testSigners(t) {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewStatement(signer, "abcd", time.Unix(1700000000, 0))
+			if err != nil {
+				t.Fatalf("NewStatement: %v", err)
+			}
+			if _, err = s.MarshalIndent(); err != nil {
+				t.Fatalf("MarshalIndent: %v", err)
+			}
+
+			tampered := *s
+			tampered.Message = "I control everything."
+			if err = tampered.Verify(); err == nil {
+				t.Fatalf("Verify: accepted tampered message")
+			}
+
+			tampered = *s
+			tampered.Address = "oasis1qphdkldpttpsj2j3l9sde9h26cwpfwqwwuhvruyu"
+			if err = tampered.Verify(); err == nil {
+				t.Fatalf("Verify: accepted mismatched address")
+			}
+		})
+	}
+}
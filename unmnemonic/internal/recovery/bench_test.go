@@ -0,0 +1,137 @@
+package recovery
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/address"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/wallet"
+)
+
+const (
+	benchTarget = "oasis1qryqqccycvckcxp453tflalujvlf78xymcdqw4vz"
+
+	throughputDuration = 200 * time.Millisecond
+)
+
+// benchStages are the stages of a recovery search, from cheapest to
+// most expensive.
+func benchStages(tb testing.TB) []struct {
+	name string
+	fn   func()
+} {
+	d, err := wallet.New(wallet.SchemeADR0008)
+	if err != nil {
+		tb.Fatalf("wallet.New: %v", err)
+	}
+	mnemonic := []byte(testMnemonic)
+	seed := bip39.MnemonicToSeed(nil, mnemonic)
+	path := d.AccountPath(0)
+	pk := make(ed25519.PublicKey, ed25519.PublicKeySize)
+
+	passphraseCheck, err := NewPassphraseCheck(d, mnemonic, benchTarget, []uint32{0})
+	if err != nil {
+		tb.Fatalf("NewPassphraseCheck: %v", err)
+	}
+	words := strings.Fields(testMnemonic)
+	words[11] = "?"
+	missingWordSpace, err := NewMissingWordSpace(words)
+	if err != nil {
+		tb.Fatalf("NewMissingWordSpace: %v", err)
+	}
+	mnemonicCheck, err := NewMnemonicCheck(d, nil, benchTarget, []uint32{0})
+	if err != nil {
+		tb.Fatalf("NewMnemonicCheck: %v", err)
+	}
+	var i uint64
+
+	return []struct {
+		name string
+		fn   func()
+	}{
+		{"ChecksumFilter", func() {
+			_, _ = bip39.ValidateAndExpandMnemonic([]byte(missingWordSpace.At(i % missingWordSpace.Len())))
+			i++
+		}},
+		{"AddressFromPublicKey", func() {
+			_, _ = address.FromPublicKey(pk)
+		}},
+		{"DeriveADR0008", func() {
+			_, _ = d.Derive(seed, path)
+		}},
+		{"MnemonicToSeed", func() {
+			_ = bip39.MnemonicToSeed(nil, mnemonic)
+		}},
+		{"PassphraseCandidate", func() {
+			_, _ = passphraseCheck("candidate")
+		}},
+		{"MissingWordCandidate", func() {
+			// Only 1 in 16 candidates makes it past the checksum filter.
+			_, _ = mnemonicCheck(missingWordSpace.At(i % missingWordSpace.Len()))
+			i++
+		}},
+	}
+}
+
+func TestThroughput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping throughput measurement in short mode")
+	}
+
+	// Single core throughput, to be multiplied by the number of workers.
+	for _, stage := range benchStages(t) {
+		var n int
+		start := time.Now()
+		for time.Since(start) < throughputDuration {
+			stage.fn()
+			n++
+		}
+		t.Logf("%-22s %12.0f ops/s", stage.name, float64(n)/time.Since(start).Seconds())
+	}
+}
+
+func BenchmarkStages(b *testing.B) {
+	for _, stage := range benchStages(b) {
+		b.Run(stage.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				stage.fn()
+			}
+		})
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	d, err := wallet.New(wallet.SchemeADR0008)
+	if err != nil {
+		b.Fatalf("wallet.New: %v", err)
+	}
+	check, err := NewPassphraseCheck(d, []byte(testMnemonic), benchTarget, []uint32{0})
+	if err != nil {
+		b.Fatalf("NewPassphraseCheck: %v", err)
+	}
+	space, err := NewMaskSpace(strings.Repeat("?", 5), "")
+	if err != nil {
+		b.Fatalf("NewMaskSpace: %v", err)
+	}
+
+	start := time.Now()
+	if _, err = Search(context.Background(), &limitedSpace{space, uint64(b.N)}, check, nil); err != nil {
+		b.Fatalf("Search: %v", err)
+	}
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "candidates/s")
+}
+
+type limitedSpace struct {
+	Space
+	n uint64
+}
+
+func (s *limitedSpace) Len() uint64 {
+	return s.n
+}
@@ -0,0 +1,85 @@
+package recovery
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/address"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/wallet"
+)
+
+// NewPassphraseCheck returns a CheckFunc that tests BIP-39 passphrase
+// candidates, by deriving the wallets with the provided indexes from
+// the mnemonic and candidate, and comparing them with the target address.
+func NewPassphraseCheck(d wallet.Deriver, mnemonic []byte, target string, indexes []uint32) (CheckFunc, error) {
+	expanded, err := bip39.ValidateAndExpandMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	isMatch, err := newSeedCheck(d, target, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(candidate string) (bool, error) {
+		return isMatch(bip39.MnemonicToSeed([]byte(candidate), expanded))
+	}, nil
+}
+
+// NewMnemonicCheck returns a CheckFunc that tests mnemonic candidates
+// (eg: from NewMissingWordSpace or NewWordOrderSpace), by deriving the
+// wallets with the provided indexes from the candidate and passphrase,
+// and comparing them with the target address.  Candidates with an invalid
+// checksum are rejected before the (expensive) seed derivation.
+func NewMnemonicCheck(d wallet.Deriver, passphrase []byte, target string, indexes []uint32) (CheckFunc, error) {
+	isMatch, err := newSeedCheck(d, target, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(candidate string) (bool, error) {
+		mnemonic, err := bip39.ValidateAndExpandMnemonic([]byte(candidate))
+		if err != nil {
+			// Almost all candidates end up here, so this is not an
+			// error.
+			return false, nil
+		}
+		return isMatch(bip39.MnemonicToSeed(passphrase, mnemonic))
+	}, nil
+}
+
+func newSeedCheck(d wallet.Deriver, target string, indexes []uint32) (func([]byte) (bool, error), error) {
+	if _, err := address.Decode(target); err != nil {
+		return nil, fmt.Errorf("recovery: invalid target address: %w", err)
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("recovery: no wallet indexes to check")
+	}
+
+	return func(seed []byte) (bool, error) {
+		keys, err := wallet.DeriveAccounts(d, seed, indexes)
+		switch {
+		case isRejectedSeed(err):
+			// Some schemes (eg: BIP32-Ed25519) reject a fraction of
+			// seeds, which therefore can't be the wallet's.
+			return false, nil
+		case err != nil:
+			return false, err
+		}
+		for _, key := range keys {
+			if key.Address == target {
+				return true, nil
+			}
+		}
+		return false, nil
+	}, nil
+}
+
+// isRejectedSeed returns true iff the derivation error is due to the
+// scheme rejecting the seed (or a key derived from it), as opposed to
+// an actual failure.
+func isRejectedSeed(err error) bool {
+	return errors.Is(err, bip32.ErrInvalidMasterKey) || errors.Is(err, bip32.ErrDivisibleByBaseOrder)
+}
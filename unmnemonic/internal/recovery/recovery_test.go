@@ -0,0 +1,541 @@
+package recovery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/wallet"
+)
+
+const testMnemonic = "legal winner thank year wave sausage worth useful legal winner thank yellow"
+
+func allCandidates(space Space) []string {
+	var v []string
+	for i := uint64(0); i < space.Len(); i++ {
+		v = append(v, space.At(i))
+	}
+	return v
+}
+
+func TestSpaces(t *testing.T) {
+	t.Run("Wordlist", func(t *testing.T) {
+		space, err := NewWordlistSpace(strings.NewReader("foo\r\nbar\n\nbaz"))
+		if err != nil {
+			t.Fatalf("NewWordlistSpace: %v", err)
+		}
+		if v, expected := allCandidates(space), []string{"foo", "bar", "", "baz"}; !reflect.DeepEqual(v, expected) {
+			t.Fatalf("unexpected candidates: %q", v)
+		}
+
+		if _, err = NewWordlistSpace(strings.NewReader("")); err == nil {
+			t.Fatalf("NewWordlistSpace: accepted empty wordlist")
+		}
+	})
+
+	t.Run("Mask", func(t *testing.T) {
+		space, err := NewMaskSpace(`a?\?b?`, "01")
+		if err != nil {
+			t.Fatalf("NewMaskSpace: %v", err)
+		}
+		if v, expected := allCandidates(space), []string{"a0?b0", "a0?b1", "a1?b0", "a1?b1"}; !reflect.DeepEqual(v, expected) {
+			t.Fatalf("unexpected candidates: %q", v)
+		}
+
+		if space, err = NewMaskSpace("Summer20??!", ""); err != nil {
+			t.Fatalf("NewMaskSpace: %v", err)
+		}
+		if l, expected := space.Len(), uint64(len(DefaultMaskCharset)*len(DefaultMaskCharset)); l != expected {
+			t.Fatalf("unexpected length: %d (expected %d)", l, expected)
+		}
+
+		if _, err = NewMaskSpace(`foo\`, ""); err == nil {
+			t.Fatalf("NewMaskSpace: accepted trailing escape")
+		}
+		if _, err = NewMaskSpace(strings.Repeat("?", 20), ""); err == nil {
+			t.Fatalf("NewMaskSpace: accepted overly large space")
+		}
+	})
+
+	t.Run("Mutation", func(t *testing.T) {
+		space, err := NewMutationSpace("as", MutationOptions{Case: true})
+		if err != nil {
+			t.Fatalf("NewMutationSpace: %v", err)
+		}
+		if v, expected := allCandidates(space), []string{"as", "aS", "As", "AS"}; !reflect.DeepEqual(v, expected) {
+			t.Fatalf("unexpected candidates: %q", v)
+		}
+
+		if space, err = NewMutationSpace("as", MutationOptions{Case: true, Leet: true}); err != nil {
+			t.Fatalf("NewMutationSpace: %v", err)
+		}
+		if l := space.Len(); l != 16 {
+			t.Fatalf("unexpected length: %d", l)
+		}
+		if v := allCandidates(space); v[15] != "@$" {
+			t.Fatalf("unexpected last candidate: %q", v[15])
+		}
+	})
+
+	t.Run("Concat", func(t *testing.T) {
+		a, _ := NewMaskSpace("a?", "12")
+		b := NewListSpace([]string{"b"})
+		c, _ := NewMaskSpace("c?", "123")
+		space, err := Concat(a, NewListSpace(nil), b, c)
+		if err != nil {
+			t.Fatalf("Concat: %v", err)
+		}
+		if v, expected := allCandidates(space), []string{"a1", "a2", "b", "c1", "c2", "c3"}; !reflect.DeepEqual(v, expected) {
+			t.Fatalf("unexpected candidates: %q", v)
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	space, _ := NewMaskSpace("????", "0123456789")
+	const target = "7351"
+
+	t.Run("Found", func(t *testing.T) {
+		result, err := Search(context.Background(), space, func(candidate string) (bool, error) {
+			return candidate == target, nil
+		}, &Options{Workers: 4})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if result == nil || result.Candidate != target || result.Index != 7351 {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		var checked uint64
+		result, err := Search(context.Background(), space, func(candidate string) (bool, error) {
+			atomic.AddUint64(&checked, 1)
+			return false, nil
+		}, nil)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if result != nil {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+		if checked != space.Len() {
+			t.Fatalf("checked %d candidates (expected %d)", checked, space.Len())
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		opts := &Options{
+			Workers:        2,
+			ID:             "test",
+			Key:            []byte("test key"),
+			CheckpointFile: filepath.Join(t.TempDir(), "checkpoint.json"),
+		}
+
+		// Cancel the search part way through, which should leave a
+		// checkpoint behind.
+		ctx, cancel := context.WithCancel(context.Background())
+		var checked uint64
+		_, err := Search(ctx, space, func(candidate string) (bool, error) {
+			if atomic.AddUint64(&checked, 1) == 5000 {
+				cancel()
+			}
+			return false, nil
+		}, opts)
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Search: unexpected error: %v", err)
+		}
+		cp, err := resumeCheckpoint(opts, space, 0, space.Len())
+		if err != nil {
+			t.Fatalf("resumeCheckpoint: %v", err)
+		}
+		start := cp.Next
+		if start == 0 || start > 5000 {
+			t.Fatalf("unexpected checkpoint: %d", start)
+		}
+		fi, err := os.Stat(opts.CheckpointFile)
+		if err != nil {
+			t.Fatalf("os.Stat: %v", err)
+		}
+		if mode := fi.Mode().Perm(); mode != 0o600 {
+			t.Fatalf("unexpected checkpoint file mode: %o", mode)
+		}
+		b, err := os.ReadFile(opts.CheckpointFile)
+		if err != nil {
+			t.Fatalf("os.ReadFile: %v", err)
+		}
+		if bytes.Contains(b, opts.Key) {
+			t.Fatalf("checkpoint contains the key")
+		}
+
+		// The same search with a different key must not resume from the
+		// checkpoint.
+		if _, err = Search(context.Background(), space, func(string) (bool, error) {
+			return false, nil
+		}, &Options{ID: opts.ID, Key: []byte("other key"), CheckpointFile: opts.CheckpointFile}); err == nil {
+			t.Fatalf("Search: resumed from a checkpoint with a different key")
+		}
+
+		// A different search must not resume from the checkpoint.
+		if _, err = Search(context.Background(), space, func(string) (bool, error) {
+			return false, nil
+		}, &Options{ID: "other", Key: opts.Key, CheckpointFile: opts.CheckpointFile}); err == nil {
+			t.Fatalf("Search: resumed from another search's checkpoint")
+		}
+
+		// Resuming should not revisit the checkpointed candidates.
+		var minIndex uint64 = space.Len()
+		result, err := Search(context.Background(), space, func(candidate string) (bool, error) {
+			// The space enumerates the candidates in numeric order.
+			if i, _ := strconv.ParseUint(candidate, 10, 64); i < minIndex {
+				minIndex = i
+			}
+			return candidate == target, nil
+		}, &Options{Workers: 1, ID: opts.ID, Key: opts.Key, CheckpointFile: opts.CheckpointFile})
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if minIndex < start {
+			t.Fatalf("resumed search revisited candidate %d", minIndex)
+		}
+		if result == nil || result.Candidate != target {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+		if _, err = os.Stat(opts.CheckpointFile); !os.IsNotExist(err) {
+			t.Fatalf("checkpoint not removed after completion: %v", err)
+		}
+	})
+
+	t.Run("Shards", func(t *testing.T) {
+		const shards = 3
+		var checked uint64
+		for shard := 0; shard < shards; shard++ {
+			lo, hi, err := shardRange(space.Len(), shard, shards)
+			if err != nil {
+				t.Fatalf("shardRange: %v", err)
+			}
+			result, err := Search(context.Background(), space, func(candidate string) (bool, error) {
+				i, _ := strconv.ParseUint(candidate, 10, 64)
+				if i < lo || i >= hi {
+					return false, fmt.Errorf("candidate %d outside of shard [%d, %d)", i, lo, hi)
+				}
+				atomic.AddUint64(&checked, 1)
+				return false, nil
+			}, &Options{Shard: shard, Shards: shards})
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			if result != nil {
+				t.Fatalf("unexpected result: %+v", result)
+			}
+		}
+		if checked != space.Len() {
+			t.Fatalf("checked %d candidates (expected %d)", checked, space.Len())
+		}
+
+		if _, err := Search(context.Background(), space, func(string) (bool, error) {
+			return false, nil
+		}, &Options{Shard: shards, Shards: shards}); err == nil {
+			t.Fatalf("Search: accepted invalid shard")
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		errCheck := errors.New("check failed")
+		if _, err := Search(context.Background(), space, func(string) (bool, error) {
+			return false, errCheck
+		}, nil); !errors.Is(err, errCheck) {
+			t.Fatalf("Search: unexpected error: %v", err)
+		}
+	})
+}
+
+func TestSpaceFingerprint(t *testing.T) {
+	mustSpace := func(space Space, err error) Space {
+		if err != nil {
+			t.Fatalf("failed to create space: %v", err)
+		}
+		return space
+	}
+	mutations := func(guesses ...string) Space {
+		var spaces []Space
+		for _, guess := range guesses {
+			spaces = append(spaces, mustSpace(NewMutationSpace(guess, MutationOptions{Case: true})))
+		}
+		return mustSpace(Concat(spaces...))
+	}
+	words := strings.Fields(testMnemonic)
+
+	testKey, testSalt := []byte("test key"), make([]byte, checkpointSaltSize)
+	testFingerprint := func(id string, space Space, shard, shards int) string {
+		return spaceFingerprint(testKey, testSalt, id, space, shard, shards)
+	}
+
+	base := testFingerprint("test", mutations("abc", "def", "ghi"), 0, 0)
+	if fp := testFingerprint("test", mutations("abc", "def", "ghi"), 0, 0); fp != base {
+		t.Fatalf("fingerprint of an identical space changed")
+	}
+
+	for _, tc := range []struct {
+		name  string
+		fp    string
+		other string
+	}{
+		{
+			"MiddleGuess",
+			base,
+			testFingerprint("test", mutations("abc", "xyz", "ghi"), 0, 0),
+		},
+		{
+			"GuessOrder",
+			base,
+			testFingerprint("test", mutations("abc", "ghi", "def"), 0, 0),
+		},
+		{
+			"Shard",
+			testFingerprint("test", mutations("abc", "def", "ghi"), 0, 2),
+			testFingerprint("test", mutations("abc", "def", "ghi"), 1, 2),
+		},
+		{
+			"WordlistMiddleLine",
+			testFingerprint("test", mustSpace(NewWordlistSpace(strings.NewReader("a\nb\nc\n"))), 0, 0),
+			testFingerprint("test", mustSpace(NewWordlistSpace(strings.NewReader("a\nx\nc\n"))), 0, 0),
+		},
+		{
+			"Charset",
+			testFingerprint("test", mustSpace(NewMaskSpace("a??z", "0123")), 0, 0),
+			testFingerprint("test", mustSpace(NewMaskSpace("a??z", "0213")), 0, 0),
+		},
+		{
+			"WordOrderPositions",
+			testFingerprint("test", mustSpace(NewWordOrderSpace(words, []int{1, 2, 3})), 0, 0),
+			testFingerprint("test", mustSpace(NewWordOrderSpace(words, []int{1, 3, 2})), 0, 0),
+		},
+		{
+			"Key",
+			base,
+			spaceFingerprint([]byte("other key"), testSalt, "test", mutations("abc", "def", "ghi"), 0, 0),
+		},
+		{
+			"Salt",
+			base,
+			spaceFingerprint(testKey, append([]byte{1}, testSalt[1:]...), "test", mutations("abc", "def", "ghi"), 0, 0),
+		},
+		{
+			"Encoding",
+			testFingerprint("test", NewListSpace([]string{"ab", "c"}), 0, 0),
+			testFingerprint("test", NewListSpace([]string{"a", "bc"}), 0, 0),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.fp == tc.other {
+				t.Fatalf("different spaces have the same fingerprint")
+			}
+		})
+	}
+}
+
+func TestMnemonicSpaces(t *testing.T) {
+	words := strings.Fields(testMnemonic)
+
+	t.Run("MissingWord", func(t *testing.T) {
+		partial := append([]string{}, words...)
+		partial[1], partial[11] = "?", "?"
+		space, err := NewMissingWordSpace(partial)
+		if err != nil {
+			t.Fatalf("NewMissingWordSpace: %v", err)
+		}
+		if l := space.Len(); l != 2048*2048 {
+			t.Fatalf("unexpected length: %d", l)
+		}
+		if v := space.At(0); v != "legal abandon thank year wave sausage worth useful legal winner thank abandon" {
+			t.Fatalf("unexpected first candidate: %s", v)
+		}
+
+		partial[0] = "notaword"
+		if _, err = NewMissingWordSpace(partial); err == nil {
+			t.Fatalf("NewMissingWordSpace: accepted invalid word")
+		}
+	})
+
+	t.Run("WordOrder", func(t *testing.T) {
+		space, err := NewWordOrderSpace([]string{"a", "b", "c", "d"}, nil)
+		if err == nil {
+			t.Fatalf("NewWordOrderSpace: accepted invalid words")
+		}
+
+		if space, err = NewWordOrderSpace(words[:4], []int{3, 0, 1}); err != nil {
+			t.Fatalf("NewWordOrderSpace: %v", err)
+		}
+		expected := []string{
+			"legal winner thank year",
+			"winner year thank legal",
+			"year legal thank winner",
+			"winner legal thank year",
+			"year winner thank legal",
+			"legal year thank winner",
+		}
+		seen := make(map[string]bool)
+		for _, v := range allCandidates(space) {
+			seen[v] = true
+		}
+		if len(seen) != len(expected) {
+			t.Fatalf("unexpected candidates: %q", allCandidates(space))
+		}
+		for _, v := range expected {
+			if !seen[v] {
+				t.Fatalf("missing candidate: %s", v)
+			}
+		}
+
+		if _, err = NewWordOrderSpace(words, []int{0, 0}); err == nil {
+			t.Fatalf("NewWordOrderSpace: accepted duplicate position")
+		}
+		if _, err = NewWordOrderSpace(append(words, words...), nil); err == nil {
+			t.Fatalf("NewWordOrderSpace: accepted overly large space")
+		}
+	})
+}
+
+func TestMnemonic(t *testing.T) {
+	d, err := wallet.New(wallet.SchemeADR0008)
+	if err != nil {
+		t.Fatalf("wallet.New: %v", err)
+	}
+	seed := bip39.MnemonicToSeed(nil, []byte(testMnemonic))
+	key, err := d.Derive(seed, d.AccountPath(0))
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+
+	var derived uint64
+	isMatch, err := NewMnemonicCheck(d, nil, key.Address, []uint32{0})
+	if err != nil {
+		t.Fatalf("NewMnemonicCheck: %v", err)
+	}
+	check := func(candidate string) (bool, error) {
+		if _, err := bip39.ValidateAndExpandMnemonic([]byte(candidate)); err == nil {
+			atomic.AddUint64(&derived, 1)
+		}
+		return isMatch(candidate)
+	}
+
+	words := strings.Fields(testMnemonic)
+	t.Run("MissingWord", func(t *testing.T) {
+		partial := append([]string{}, words...)
+		partial[11] = "?"
+		space, err := NewMissingWordSpace(partial)
+		if err != nil {
+			t.Fatalf("NewMissingWordSpace: %v", err)
+		}
+		result, err := Search(context.Background(), space, check, nil)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if result == nil || result.Candidate != testMnemonic {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+		// Only candidates with a valid checksum (1 in 16 for 12 words)
+		// should make it to the seed derivation.
+		if derived > 2048/16 {
+			t.Fatalf("derived seeds for %d candidates", derived)
+		}
+	})
+
+	t.Run("WordOrder", func(t *testing.T) {
+		swapped := append([]string{}, words...)
+		swapped[2], swapped[9] = swapped[9], swapped[2]
+		space, err := NewWordOrderSpace(swapped, []int{2, 5, 9})
+		if err != nil {
+			t.Fatalf("NewWordOrderSpace: %v", err)
+		}
+		result, err := Search(context.Background(), space, check, nil)
+		if err != nil {
+			t.Fatalf("Search: %v", err)
+		}
+		if result == nil || result.Candidate != testMnemonic {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+	})
+}
+
+func TestPassphrase(t *testing.T) {
+	d, err := wallet.New(wallet.SchemeADR0008)
+	if err != nil {
+		t.Fatalf("wallet.New: %v", err)
+	}
+
+	const passphrase = "Summer2024!"
+	seed := bip39.MnemonicToSeed([]byte(passphrase), []byte(testMnemonic))
+	key, err := d.Derive(seed, d.AccountPath(1))
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+
+	check, err := NewPassphraseCheck(d, []byte(testMnemonic), key.Address, []uint32{0, 1})
+	if err != nil {
+		t.Fatalf("NewPassphraseCheck: %v", err)
+	}
+
+	space, err := NewMaskSpace("Summer20??!", "0123456789")
+	if err != nil {
+		t.Fatalf("NewMaskSpace: %v", err)
+	}
+	result, err := Search(context.Background(), space, check, nil)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if result == nil || result.Candidate != passphrase {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if _, err = NewPassphraseCheck(d, []byte(testMnemonic), "oasis1invalid", []uint32{0}); err == nil {
+		t.Fatalf("NewPassphraseCheck: accepted invalid address")
+	}
+
+	// Derivation failures are errors, not mismatches.
+	check, err = NewPassphraseCheck(d, []byte(testMnemonic), key.Address, []uint32{bip32.HardenedIndexOffset})
+	if err != nil {
+		t.Fatalf("NewPassphraseCheck: %v", err)
+	}
+	if _, err = check(passphrase); err == nil {
+		t.Fatalf("check: failed to return derivation error")
+	}
+}
+
+func TestPassphraseRejectedSeed(t *testing.T) {
+	// BIP32-Ed25519 discards about half of the seeds, which are
+	// mismatches, not errors.
+	d, err := wallet.New(wallet.SchemeBIP32Ed25519)
+	if err != nil {
+		t.Fatalf("wallet.New: %v", err)
+	}
+	check, err := NewPassphraseCheck(d, []byte(testMnemonic), "oasis1qqx0wgxjwlw3jwatuwqj6582hdm9rjs4pcnvzz66", []uint32{0})
+	if err != nil {
+		t.Fatalf("NewPassphraseCheck: %v", err)
+	}
+
+	var rejected int
+	for i := 0; i < 16; i++ {
+		candidate := strconv.Itoa(i)
+		if _, err = bip32.NewRoot(bip39.MnemonicToSeed([]byte(candidate), []byte(testMnemonic))); err != nil {
+			rejected++
+		}
+		if _, err = check(candidate); err != nil {
+			t.Fatalf("check(%s): %v", candidate, err)
+		}
+	}
+	if rejected == 0 {
+		t.Fatalf("no candidate seeds were rejected")
+	}
+}
@@ -0,0 +1,398 @@
+package recovery
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	checkpointVersion  = 3
+	checkpointSaltSize = 32
+
+	defaultChunkSize          = 64
+	defaultCheckpointInterval = 30 * time.Second
+)
+
+// CheckFunc tests a candidate, returning true iff it is the one being
+// searched for.  It is called concurrently from multiple goroutines.
+type CheckFunc func(candidate string) (bool, error)
+
+// Options are the search options.
+type Options struct {
+	// Workers is the number of concurrent workers (default: the number
+	// of CPUs usable by the process).
+	Workers int
+
+	// Shard and Shards split the space into Shards equal contiguous
+	// ranges, of which only the Shard-th (0-based) is searched, so that
+	// a search can be distributed across processes or machines.
+	Shard  int
+	Shards int
+
+	// ID identifies the search (eg: the target address), and is used to
+	// detect checkpoints that belong to a different search.
+	ID string
+
+	// Key is a secret that is never written to the checkpoint file (eg:
+	// the mnemonic, for a passphrase search), that the checkpoint's
+	// fingerprint of the search is keyed with, so that the fingerprint
+	// can't be used to brute-force the candidates (eg: passphrase
+	// guesses) offline.
+	Key []byte
+
+	// CheckpointFile is the path of the checkpoint file (optional).  If
+	// it exists, the search resumes from it.
+	CheckpointFile string
+
+	// CheckpointInterval is how often the checkpoint file is written
+	// (default: 30s).
+	CheckpointInterval time.Duration
+
+	// Progress is called with the number of candidates in the shard
+	// checked so far every time the checkpoint file is written (optional).
+	Progress func(done, total uint64)
+}
+
+// Result is a successful search result.
+type Result struct {
+	// Index is the index of the candidate in the space.
+	Index uint64
+	// Candidate is the matching candidate.
+	Candidate string
+}
+
+type checkpoint struct {
+	Version     int    `json:"version"`
+	Salt        string `json:"salt"`
+	Fingerprint string `json:"fingerprint"`
+	Next        uint64 `json:"next"`
+}
+
+// Search searches (the shard of) space for the candidate accepted by
+// check, and returns nil if no candidate matches.  The search is
+// checkpointed on cancellation (eg: on SIGINT), on error, and periodically.
+// The checkpoint file is removed once the search completes.
+func Search(ctx context.Context, space Space, check CheckFunc, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	interval := opts.CheckpointInterval
+	if interval <= 0 {
+		interval = defaultCheckpointInterval
+	}
+
+	lo, hi, err := shardRange(space.Len(), opts.Shard, opts.Shards)
+	if err != nil {
+		return nil, err
+	}
+	if lo == hi {
+		return nil, nil
+	}
+	cp, err := resumeCheckpoint(opts, space, lo, hi)
+	if err != nil {
+		return nil, err
+	}
+	start := cp.Next
+
+	s := &searcher{
+		space:    space,
+		check:    check,
+		lo:       lo,
+		hi:       hi,
+		start:    start,
+		frontier: start,
+		done:     make(map[uint64]uint64),
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.worker(workerCtx, cancel)
+		}()
+	}
+
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+waitLoop:
+	for {
+		select {
+		case <-ticker.C:
+			if err = s.saveCheckpoint(opts, cp); err != nil {
+				cancel()
+				<-workersDone
+				return nil, err
+			}
+		case <-workersDone:
+			break waitLoop
+		}
+	}
+
+	switch {
+	case s.result != nil:
+	case s.err != nil:
+		_ = s.saveCheckpoint(opts, cp)
+		return nil, s.err
+	case ctx.Err() != nil:
+		if err = s.saveCheckpoint(opts, cp); err != nil {
+			return nil, err
+		}
+		return nil, ctx.Err()
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(hi-lo, hi-lo)
+	}
+	if opts.CheckpointFile != "" {
+		if err = os.Remove(opts.CheckpointFile); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("recovery: failed to remove checkpoint: %w", err)
+		}
+	}
+
+	return s.result, nil
+}
+
+type searcher struct {
+	space Space
+	check CheckFunc
+	lo    uint64
+	hi    uint64
+
+	start     uint64
+	nextChunk uint64
+
+	l        sync.Mutex
+	frontier uint64
+	done     map[uint64]uint64
+	result   *Result
+	err      error
+}
+
+func (s *searcher) worker(ctx context.Context, cancel context.CancelFunc) {
+	for ctx.Err() == nil {
+		chunkStart := s.start + (atomic.AddUint64(&s.nextChunk, 1)-1)*defaultChunkSize
+		if chunkStart >= s.hi || chunkStart < s.start {
+			return
+		}
+		chunkEnd := chunkStart + defaultChunkSize
+		if chunkEnd > s.hi || chunkEnd < chunkStart {
+			chunkEnd = s.hi
+		}
+
+		for i := chunkStart; i < chunkEnd; i++ {
+			if ctx.Err() != nil {
+				// The chunk is incomplete, so it is not marked done,
+				// and will be redone on resume.
+				return
+			}
+
+			candidate := s.space.At(i)
+			ok, err := s.check(candidate)
+			if err != nil || ok {
+				s.l.Lock()
+				if err != nil && s.err == nil {
+					s.err = fmt.Errorf("recovery: failed to check candidate %d: %w", i, err)
+				}
+				if ok && (s.result == nil || i < s.result.Index) {
+					s.result = &Result{
+						Index:     i,
+						Candidate: candidate,
+					}
+				}
+				s.l.Unlock()
+				cancel()
+				return
+			}
+		}
+
+		s.markDone(chunkStart, chunkEnd)
+	}
+}
+
+func (s *searcher) markDone(chunkStart, chunkEnd uint64) {
+	s.l.Lock()
+	defer s.l.Unlock()
+
+	// Chunks complete out of order, so the checkpoint can only advance
+	// past contiguous completed chunks.
+	s.done[chunkStart] = chunkEnd
+	for {
+		end, ok := s.done[s.frontier]
+		if !ok {
+			break
+		}
+		delete(s.done, s.frontier)
+		s.frontier = end
+	}
+}
+
+func (s *searcher) saveCheckpoint(opts *Options, cp *checkpoint) error {
+	s.l.Lock()
+	next := s.frontier
+	s.l.Unlock()
+
+	if opts.Progress != nil {
+		opts.Progress(next-s.lo, s.hi-s.lo)
+	}
+	if opts.CheckpointFile == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(&checkpoint{
+		Version:     cp.Version,
+		Salt:        cp.Salt,
+		Fingerprint: cp.Fingerprint,
+		Next:        next,
+	})
+	if err != nil {
+		return fmt.Errorf("recovery: failed to serialize checkpoint: %w", err)
+	}
+
+	// Write then rename, so that a crash never leaves a torn checkpoint.
+	// The mode is set explicitly, as a stale temporary file would keep
+	// its mode.
+	tmp := opts.CheckpointFile + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("recovery: failed to write checkpoint: %w", err)
+	}
+	if err = f.Chmod(0o600); err == nil {
+		_, err = f.Write(b)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("recovery: failed to write checkpoint: %w", err)
+	}
+	if err = os.Rename(tmp, opts.CheckpointFile); err != nil {
+		return fmt.Errorf("recovery: failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// resumeCheckpoint returns the checkpoint to resume the search from, or a
+// new checkpoint (with a fresh salt) at the start of the shard, if there
+// is no checkpoint file.
+func resumeCheckpoint(opts *Options, space Space, lo, hi uint64) (*checkpoint, error) {
+	cp, err := loadCheckpoint(opts.CheckpointFile)
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil {
+		salt := make([]byte, checkpointSaltSize)
+		if _, err = rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("recovery: failed to generate checkpoint salt: %w", err)
+		}
+		return &checkpoint{
+			Version:     checkpointVersion,
+			Salt:        hex.EncodeToString(salt),
+			Fingerprint: spaceFingerprint(opts.Key, salt, opts.ID, space, opts.Shard, opts.Shards),
+			Next:        lo,
+		}, nil
+	}
+
+	salt, err := hex.DecodeString(cp.Salt)
+	if err != nil || len(salt) != checkpointSaltSize {
+		return nil, fmt.Errorf("recovery: malformed checkpoint salt")
+	}
+	fingerprint := spaceFingerprint(opts.Key, salt, opts.ID, space, opts.Shard, opts.Shards)
+	if !hmac.Equal([]byte(cp.Fingerprint), []byte(fingerprint)) || cp.Next < lo || cp.Next > hi {
+		return nil, fmt.Errorf("recovery: checkpoint is for a different search")
+	}
+	return cp, nil
+}
+
+// loadCheckpoint loads the checkpoint file, and returns nil if it does not
+// exist.
+func loadCheckpoint(fn string) (*checkpoint, error) {
+	if fn == "" {
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(fn)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("recovery: failed to read checkpoint: %w", err)
+	}
+
+	var cp checkpoint
+	if err = json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("recovery: malformed checkpoint: %w", err)
+	}
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("recovery: unsupported checkpoint version: %d", cp.Version)
+	}
+
+	return &cp, nil
+}
+
+// spaceFingerprint returns a fingerprint of the search, so that a
+// checkpoint is never applied to a different search.  It covers the
+// search ID, the shard, and the full definition of the space (and thus
+// the order of the candidates), but the candidates themselves are not
+// stored in the checkpoint.
+//
+// The fingerprint is a HMAC keyed with the search's secret key, over the
+// checkpoint's random salt and the search, as the definition includes
+// low entropy inputs (eg: passphrase guesses) that could otherwise be
+// brute-forced from the fingerprint.
+func spaceFingerprint(key, salt []byte, id string, space Space, shard, shards int) string {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write(salt)
+	writeDefinitionString(h, id)
+	writeDefinitionUint(h, uint64(shard))
+	writeDefinitionUint(h, uint64(shards))
+	writeDefinitionUint(h, space.Len())
+	space.WriteDefinition(h)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// shardRange returns the range of candidate indexes [lo, hi) in a shard.
+func shardRange(total uint64, shard, shards int) (uint64, uint64, error) {
+	if shards <= 1 {
+		if shard != 0 {
+			return 0, 0, fmt.Errorf("recovery: invalid shard: %d", shard)
+		}
+		return 0, total, nil
+	}
+	if shard < 0 || shard >= shards {
+		return 0, 0, fmt.Errorf("recovery: invalid shard: %d/%d", shard, shards)
+	}
+
+	boundary := func(i int) uint64 {
+		// total * i / shards, without overflowing.
+		hi, lo := bits.Mul64(total, uint64(i))
+		q, _ := bits.Div64(hi, lo, uint64(shards))
+		return q
+	}
+	return boundary(shard), boundary(shard + 1), nil
+}
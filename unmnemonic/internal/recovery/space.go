@@ -0,0 +1,397 @@
+// Package recovery implements searching candidate spaces for a lost
+// wallet secret (eg: a partially forgotten BIP-39 passphrase, or missing
+// or misordered mnemonic words).
+package recovery
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+	"unicode"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+)
+
+// DefaultMaskCharset is the default set of characters a mask wildcard
+// can match (printable ASCII).
+const DefaultMaskCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 !\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+// leetSubstitutions are the "leet speak" substitutions tried by the
+// mutation space.
+var leetSubstitutions = map[rune][]string{
+	'a': {"4", "@"},
+	'b': {"8"},
+	'e': {"3"},
+	'g': {"9"},
+	'i': {"1", "!"},
+	'l': {"1"},
+	'o': {"0"},
+	's': {"5", "$"},
+	't': {"7"},
+	'z': {"2"},
+}
+
+// Space is an indexable candidate space.  Being indexable is what allows
+// a search to be checkpointed and resumed.
+type Space interface {
+	// Len returns the number of candidates in the space.
+	Len() uint64
+
+	// At returns the i-th candidate.
+	At(i uint64) string
+
+	// WriteDefinition writes an unambiguous encoding of all of the inputs
+	// that define the space, and the order of its candidates, to w.  It
+	// is used to fingerprint checkpoints, without enumerating the space.
+	WriteDefinition(w io.Writer)
+}
+
+func writeDefinitionUint(w io.Writer, v uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	_, _ = w.Write(b[:])
+}
+
+func writeDefinitionString(w io.Writer, s string) {
+	writeDefinitionUint(w, uint64(len(s)))
+	_, _ = io.WriteString(w, s)
+}
+
+func writeDefinitionStrings(w io.Writer, ss []string) {
+	writeDefinitionUint(w, uint64(len(ss)))
+	for _, s := range ss {
+		writeDefinitionString(w, s)
+	}
+}
+
+type listSpace []string
+
+func (s listSpace) Len() uint64 {
+	return uint64(len(s))
+}
+
+func (s listSpace) At(i uint64) string {
+	return s[i]
+}
+
+func (s listSpace) WriteDefinition(w io.Writer) {
+	writeDefinitionString(w, "list")
+	writeDefinitionStrings(w, s)
+}
+
+// NewListSpace returns a space consisting of the provided candidates.
+func NewListSpace(candidates []string) Space {
+	return listSpace(append([]string{}, candidates...))
+}
+
+// NewWordlistSpace returns a space consisting of each line read from r.
+func NewWordlistSpace(r io.Reader) (Space, error) {
+	var candidates []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		candidates = append(candidates, strings.TrimSuffix(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("recovery: failed to read wordlist: %w", err)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("recovery: empty wordlist")
+	}
+	return listSpace(candidates), nil
+}
+
+// positionalSpace is the space of all strings where each position is
+// one of a list of options, enumerated in mixed radix.
+type positionalSpace struct {
+	options [][]string
+	sep     string
+	len     uint64
+}
+
+func (s *positionalSpace) Len() uint64 {
+	return s.len
+}
+
+func (s *positionalSpace) At(i uint64) string {
+	var b strings.Builder
+	parts := make([]string, len(s.options))
+	for pos := len(s.options) - 1; pos >= 0; pos-- {
+		radix := uint64(len(s.options[pos]))
+		parts[pos] = s.options[pos][i%radix]
+		i /= radix
+	}
+	for pos, part := range parts {
+		if pos > 0 {
+			b.WriteString(s.sep)
+		}
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+func (s *positionalSpace) WriteDefinition(w io.Writer) {
+	writeDefinitionString(w, "positional")
+	writeDefinitionString(w, s.sep)
+	writeDefinitionUint(w, uint64(len(s.options)))
+	for _, opts := range s.options {
+		writeDefinitionStrings(w, opts)
+	}
+}
+
+func newPositionalSpace(options [][]string, sep string) (Space, error) {
+	n := uint64(1)
+	for _, opts := range options {
+		hi, lo := bits.Mul64(n, uint64(len(opts)))
+		if hi != 0 || lo == math.MaxUint64 {
+			return nil, fmt.Errorf("recovery: candidate space too large")
+		}
+		n = lo
+	}
+	return &positionalSpace{
+		options: options,
+		sep:     sep,
+		len:     n,
+	}, nil
+}
+
+// NewMaskSpace returns the space matching a mask, where each `?` is a
+// wildcard matching any character in charset, and `\` escapes the next
+// character (eg: `Summer20??!`, `what\?`).
+func NewMaskSpace(mask, charset string) (Space, error) {
+	if charset == "" {
+		charset = DefaultMaskCharset
+	}
+	wildcard := make([]string, 0, len(charset))
+	for _, c := range charset {
+		wildcard = append(wildcard, string(c))
+	}
+
+	var (
+		options   [][]string
+		isEscaped bool
+	)
+	for _, c := range mask {
+		switch {
+		case isEscaped:
+			options = append(options, []string{string(c)})
+			isEscaped = false
+		case c == '\\':
+			isEscaped = true
+		case c == '?':
+			options = append(options, wildcard)
+		default:
+			options = append(options, []string{string(c)})
+		}
+	}
+	if isEscaped {
+		return nil, fmt.Errorf("recovery: mask ends with an escape")
+	}
+
+	return newPositionalSpace(options, "")
+}
+
+// MutationOptions are the options for a mutation space.
+type MutationOptions struct {
+	// Case tries both cases of each letter.
+	Case bool
+	// Leet tries common "leet speak" substitutions of each letter.
+	Leet bool
+}
+
+// NewMutationSpace returns the space of all case and/or leet mutations
+// of base (including base itself).
+func NewMutationSpace(base string, opts MutationOptions) (Space, error) {
+	options := make([][]string, 0, len(base))
+	for _, c := range base {
+		posOptions := []string{string(c)}
+		addOption := func(s string) {
+			for _, v := range posOptions {
+				if v == s {
+					return
+				}
+			}
+			posOptions = append(posOptions, s)
+		}
+
+		if opts.Case {
+			addOption(string(unicode.ToLower(c)))
+			addOption(string(unicode.ToUpper(c)))
+		}
+		if opts.Leet {
+			for _, sub := range leetSubstitutions[unicode.ToLower(c)] {
+				addOption(sub)
+			}
+		}
+		options = append(options, posOptions)
+	}
+
+	return newPositionalSpace(options, "")
+}
+
+// NewMissingWordSpace returns the space of all mnemonics matching words,
+// where each unknown word is `?`, and matches any word in the wordlist.
+// Most of the candidates have an invalid checksum.
+func NewMissingWordSpace(words []string) (Space, error) {
+	wordlist := bip39.Wordlist()
+
+	options := make([][]string, 0, len(words))
+	for _, word := range words {
+		if word == "?" {
+			options = append(options, wordlist)
+			continue
+		}
+		expanded, err := bip39.ExpandWord(word)
+		if err != nil {
+			return nil, fmt.Errorf("recovery: invalid word '%s': %w", word, err)
+		}
+		options = append(options, []string{expanded})
+	}
+
+	return newPositionalSpace(options, " ")
+}
+
+// wordOrderSpace is the space of all permutations of the words at a
+// subset of positions, enumerated in factorial number system order.
+type wordOrderSpace struct {
+	words     []string
+	positions []int
+	len       uint64
+}
+
+func (s *wordOrderSpace) Len() uint64 {
+	return s.len
+}
+
+func (s *wordOrderSpace) At(i uint64) string {
+	words := append([]string{}, s.words...)
+
+	// Decode the i-th permutation (Lehmer code) of the movable words.
+	remaining := make([]string, 0, len(s.positions))
+	for _, pos := range s.positions {
+		remaining = append(remaining, s.words[pos])
+	}
+	radices := make([]uint64, len(s.positions))
+	for j := len(s.positions) - 1; j >= 0; j-- {
+		radix := uint64(len(s.positions) - j)
+		radices[j] = i % radix
+		i /= radix
+	}
+	for j, pos := range s.positions {
+		k := radices[j]
+		words[pos] = remaining[k]
+		remaining = append(remaining[:k], remaining[k+1:]...)
+	}
+
+	return strings.Join(words, " ")
+}
+
+func (s *wordOrderSpace) WriteDefinition(w io.Writer) {
+	writeDefinitionString(w, "word-order")
+	writeDefinitionStrings(w, s.words)
+	writeDefinitionUint(w, uint64(len(s.positions)))
+	for _, pos := range s.positions {
+		writeDefinitionUint(w, uint64(pos))
+	}
+}
+
+// NewWordOrderSpace returns the space of all mnemonics consisting of
+// words, with the words at the provided (0-based) positions permuted
+// in every possible order.  If positions is empty, every word is moved.
+func NewWordOrderSpace(words []string, positions []int) (Space, error) {
+	expanded := make([]string, 0, len(words))
+	for _, word := range words {
+		w, err := bip39.ExpandWord(word)
+		if err != nil {
+			return nil, fmt.Errorf("recovery: invalid word '%s': %w", word, err)
+		}
+		expanded = append(expanded, w)
+	}
+
+	if len(positions) == 0 {
+		for pos := range words {
+			positions = append(positions, pos)
+		}
+	}
+	seen := make(map[int]bool)
+	n := uint64(1)
+	for j, pos := range positions {
+		if pos < 0 || pos >= len(words) || seen[pos] {
+			return nil, fmt.Errorf("recovery: invalid position: %d", pos)
+		}
+		seen[pos] = true
+
+		hi, lo := bits.Mul64(n, uint64(j+1))
+		if hi != 0 || lo == math.MaxUint64 {
+			return nil, fmt.Errorf("recovery: candidate space too large")
+		}
+		n = lo
+	}
+
+	return &wordOrderSpace{
+		words:     expanded,
+		positions: append([]int{}, positions...),
+		len:       n,
+	}, nil
+}
+
+type concatSpace struct {
+	spaces  []Space
+	offsets []uint64
+	len     uint64
+}
+
+func (s *concatSpace) Len() uint64 {
+	return s.len
+}
+
+func (s *concatSpace) At(i uint64) string {
+	// Find the last space whose offset is <= i.
+	lo, hi := 0, len(s.spaces)
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		if s.offsets[mid] <= i {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return s.spaces[lo].At(i - s.offsets[lo])
+}
+
+func (s *concatSpace) WriteDefinition(w io.Writer) {
+	writeDefinitionString(w, "concat")
+	writeDefinitionUint(w, uint64(len(s.spaces)))
+	for _, space := range s.spaces {
+		space.WriteDefinition(w)
+	}
+}
+
+// Concat returns the space consisting of each of the provided spaces,
+// one after another.
+func Concat(spaces ...Space) (Space, error) {
+	var (
+		cs concatSpace
+		n  uint64
+	)
+	for _, space := range spaces {
+		l := space.Len()
+		if l == 0 {
+			continue
+		}
+		if n+l < n {
+			return nil, fmt.Errorf("recovery: candidate space too large")
+		}
+		cs.spaces = append(cs.spaces, space)
+		cs.offsets = append(cs.offsets, n)
+		n += l
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("recovery: empty candidate space")
+	}
+	cs.len = n
+	return &cs, nil
+}
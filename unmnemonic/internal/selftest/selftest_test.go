@@ -0,0 +1,36 @@
+package selftest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Run(&buf); err != nil {
+		t.Fatalf("Run: %v\n%s", err, buf.String())
+	}
+	t.Logf("self-test output:\n%s", buf.String())
+}
+
+func TestRunFailure(t *testing.T) {
+	savedTests := knownAnswerTests
+	defer func() {
+		knownAnswerTests = savedTests
+	}()
+	knownAnswerTests = append(append([]knownAnswerTest{}, savedTests...), knownAnswerTest{
+		"Broken", func() error {
+			return fmt.Errorf("intentional failure")
+		},
+	})
+
+	var buf bytes.Buffer
+	if err := Run(&buf); !errors.Is(err, ErrFailed) {
+		t.Fatalf("Run: expected ErrFailed, got %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("Broken: FAILED: intentional failure")) {
+		t.Fatalf("Run: failure not reported:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,65 @@
+// Package trace implements the opt-in trace of intermediate derivation
+// state, for debugging mismatches against other implementations.
+//
+// The trace includes chain codes, which are SENSITIVE: a chain code
+// combined with any child private key compromises the sibling keys, and
+// combined with the public key enables non-hardened child derivation.  The
+// trace output must be handled with the same care as the private keys.
+// Private keys themselves are never written to the trace.
+package trace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/wallet"
+)
+
+var _ wallet.Tracer = (*Tracer)(nil)
+
+// Tracer writes the derivation trace to an io.Writer.
+type Tracer struct {
+	w io.Writer
+}
+
+// New returns a Tracer writing to w.
+func New(w io.Writer) *Tracer {
+	return &Tracer{
+		w: w,
+	}
+}
+
+// Printf writes a formatted message to the trace.
+func (t *Tracer) Printf(format string, a ...interface{}) {
+	fmt.Fprintf(t.w, "  [explain] "+format+"\n", a...)
+}
+
+// Step writes a single derivation path step to the trace.
+func (t *Tracer) Step(scheme string, index uint32, chainCode []byte, publicKey ed25519.PublicKey) {
+	isHardened := index >= bip32.HardenedIndexOffset
+	displayIndex := fmt.Sprintf("%d", index)
+	if isHardened {
+		displayIndex = fmt.Sprintf("%d'", index-bip32.HardenedIndexOffset)
+	}
+
+	t.Printf(
+		"%s: index=%s hardened=%v chain_code=%x fingerprint=%s",
+		scheme,
+		displayIndex,
+		isHardened,
+		chainCode,
+		Fingerprint(publicKey),
+	)
+}
+
+// Fingerprint returns the fingerprint of a public key, which is the
+// first 4 bytes of the SHA-256 digest of the public key, hex encoded.
+func Fingerprint(publicKey []byte) string {
+	h := sha256.Sum256(publicKey)
+	return hex.EncodeToString(h[:4])
+}
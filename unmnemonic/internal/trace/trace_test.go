@@ -0,0 +1,23 @@
+package trace
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+)
+
+func TestStep(t *testing.T) {
+	var buf bytes.Buffer
+	tr := New(&buf)
+
+	tr.Step("test", 44|bip32.HardenedIndexOffset, []byte{0xde, 0xad}, []byte("public key"))
+	tr.Step("test", 3, []byte{0xbe, 0xef}, []byte("public key"))
+
+	const expected = "" +
+		"  [explain] test: index=44' hardened=true chain_code=dead fingerprint=f569a86d\n" +
+		"  [explain] test: index=3 hardened=false chain_code=beef fingerprint=f569a86d\n"
+	if s := buf.String(); s != expected {
+		t.Fatalf("unexpected trace output: expected '%s', got '%s'", expected, s)
+	}
+}
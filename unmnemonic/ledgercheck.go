@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/oasisprotocol/tools/unmnemonic/internal/ledger"
+)
+
+func doLedgerCheck(fn string) error {
+	if fn == "" {
+		return fmt.Errorf("usage: unmnemonic %s <transcript>", cmdLedgerCheck)
+	}
+
+	f, err := os.Open(fn)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer f.Close()
+	exchanges, err := ledger.ParseTranscript(f)
+	if err != nil {
+		return err
+	}
+
+	if err = doSelftest(); err != nil {
+		return err
+	}
+	if err = doAirgapCheck(); err != nil {
+		return err
+	}
+
+	fmt.Printf(" This checks the Ledger derivation against the get address responses\n")
+	fmt.Printf(" recorded from a Ledger device.  Only ever use a TEST DEVICE whose\n")
+	fmt.Printf(" mnemonic does not and will never hold any funds.\n")
+	fmt.Printf("\n")
+
+	seed, err := askSeed()
+	if err != nil {
+		return err
+	}
+
+	results, err := ledger.Check(ledger.NewReplayTransport(exchanges), exchanges, seed)
+	if err != nil {
+		return err
+	}
+
+	var mismatches int
+	for _, r := range results {
+		if r.IsMatch() {
+			fmt.Printf(" %s: %s - ok\n", r.Path, r.Address)
+			continue
+		}
+		mismatches++
+		fmt.Printf(" %s: MISMATCH\n", r.Path)
+		fmt.Printf("   device:     %x (%s)\n", r.DevicePublicKey[:], r.DeviceAddress)
+		fmt.Printf("   unmnemonic: %x (%s)\n", r.PublicKey[:], r.Address)
+	}
+	if mismatches > 0 {
+		return fmt.Errorf("%d of %d path(s) do not match the device", mismatches, len(results))
+	}
+
+	fmt.Printf(" All %d path(s) match the device.\n", len(results))
+
+	return nil
+}
@@ -2,29 +2,51 @@ package main
 
 import (
 	"bytes"
+	"crypto"
 	"encoding/pem"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
 
-	"github.com/oasisprotocol/tools/unmnemonic/internal/address"
-	"github.com/oasisprotocol/tools/unmnemonic/internal/bip32"
-	"github.com/oasisprotocol/tools/unmnemonic/internal/bip39"
-	"github.com/oasisprotocol/tools/unmnemonic/internal/slip10"
+	"github.com/oasisprotocol/tools/unmnemonic/internal/balance"
+	"github.com/oasisprotocol/tools/unmnemonic/internal/selftest"
+	"github.com/oasisprotocol/tools/unmnemonic/internal/trace"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/wallet"
 )
 
 const (
-	algoLedger  = "Ledger"
-	algoAdr0008 = "ADR-0008"
-	algoBitpie  = "Bitpie"
+	algoLedger       = string(wallet.SchemeLedger)
+	algoAdr0008      = string(wallet.SchemeADR0008)
+	algoBitpie       = string(wallet.SchemeBitpie)
+	algoBIP32Ed25519 = string(wallet.SchemeBIP32Ed25519)
+
+	selectIndexes = "Enter index(es)"
+	selectScan    = "Scan for used accounts"
 
 	maxAccountKeyNumber = uint32(0x7fffffff)
+
+	cmdSelftest = "selftest"
+	cmdConvert  = "convert"
+
+	cmdRecoverPassphrase   = "recover-passphrase"
+	cmdRecoverMissingWords = "recover-missing-words"
+	cmdRecoverWordOrder    = "recover-word-order"
+	cmdLedgerCheck         = "ledger-check"
+)
+
+var (
+	explain bool
+
+	// tracer is the --explain derivation trace, if enabled.
+	tracer *trace.Tracer
 )
 
 func perror(err error) {
@@ -33,6 +55,44 @@ func perror(err error) {
 }
 
 func main() {
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case cmdSelftest:
+		if err := doSelftest(); err != nil {
+			perror(err)
+		}
+		return
+	case cmdConvert:
+		if err := doConvert(); err != nil {
+			perror(err)
+		}
+		return
+	case cmdRecoverPassphrase:
+		if err := doRecoverPassphrase(); err != nil {
+			perror(err)
+		}
+		return
+	case cmdRecoverMissingWords:
+		if err := doRecoverMissingWords(); err != nil {
+			perror(err)
+		}
+		return
+	case cmdRecoverWordOrder:
+		if err := doRecoverWordOrder(); err != nil {
+			perror(err)
+		}
+		return
+	case cmdLedgerCheck:
+		if err := doLedgerCheck(flag.Arg(1)); err != nil {
+			perror(err)
+		}
+		return
+	case "":
+	default:
+		perror(fmt.Errorf("unknown command: '%s'", flag.Arg(0)))
+	}
+
 	// unmnemonic is explicitly interactive because people will probably
 	// splatter their mnemonic into their shell history otherwise.
 	if err := doInteractive(); err != nil {
@@ -40,17 +100,52 @@ func main() {
 	}
 }
 
+func doSelftest() error {
+	fmt.Printf(" Running self-test:\n")
+	if err := selftest.Run(os.Stdout); err != nil {
+		fmt.Printf("\n")
+		fmt.Printf(" The self-test FAILED, this build of unmnemonic can not be trusted\n")
+		fmt.Printf(" to derive the correct keys on this machine.\n")
+		fmt.Printf("\n")
+		return err
+	}
+	fmt.Printf("\n")
+	return nil
+}
+
 func doInteractive() error {
 	// Display splash screen and warning.
 	fmt.Printf("\n")
 	fmt.Printf("  unmnemonic - Recover Oasis Network signing keys from mnemonics\n")
 	fmt.Printf("\n")
 
+	// Refuse to do anything if the derivation is broken on this machine.
+	if err := doSelftest(); err != nil {
+		return err
+	}
+
+	// Refuse to accept a mnemonic on a machine that looks online.
+	if err := doAirgapCheck(); err != nil {
+		return err
+	}
+
+	// Only enable the trace after the self-test, so that the output is
+	// limited to what is derived from the user's mnemonic.
+	if explain {
+		fmt.Printf(" WARNING:\n")
+		fmt.Printf("\n")
+		fmt.Printf("  The --explain trace includes CHAIN CODES, which together with any\n")
+		fmt.Printf("  derived private key can COMPROMISE OTHER ACCOUNTS.  Treat the\n")
+		fmt.Printf("  trace output as secret.\n")
+		fmt.Printf("\n")
+		tracer = trace.New(os.Stdout)
+	}
+
 	// Figure out the derivation scheme.
 	var algo string
 	if err := survey.AskOne(&survey.Select{
 		Message: "Which algorithm does your wallet use",
-		Options: []string{algoAdr0008, algoLedger, algoBitpie},
+		Options: []string{algoAdr0008, algoLedger, algoBitpie, algoBIP32Ed25519},
 	}, &algo); err != nil {
 		return err
 	}
@@ -75,71 +170,65 @@ func doInteractive() error {
 		}
 	}
 
-	// Deal with mnemonic entry.
-	var s string
-	if err := survey.AskOne(&survey.Input{
-		Message: "How many words is your mnemonic",
-		Default: "24",
-	}, &s, survey.WithValidator(isMnemonicLength)); err != nil {
+	// Deal with mnemonic (or entropy/seed) entry.
+	seed, err := askSeed()
+	if err != nil {
 		return err
 	}
 
-	var mnemonic []byte
-	mnemonicLength, _ := strconv.ParseUint(s, 10, 32)
-	for {
-		words := make([]string, 0, int(mnemonicLength))
-		for i := 1; i <= int(mnemonicLength); i++ {
-			if err := survey.AskOne(&survey.Password{
-				Message: fmt.Sprintf("Enter word %d", i),
-			}, &s, survey.WithValidator(isMnemonicWord)); err != nil {
-				return err
-			}
-			words = append(words, s)
-		}
-
-		var err error
-		if mnemonic, err = bip39.ValidateAndExpandMnemonic([]byte(strings.Join(words, " "))); err != nil {
-			fmt.Printf(" Invalid mnemonic: %v\n", err)
-			continue
-		}
-
-		break
+	deriveFn, err := getDeriveFn(algo)
+	if err != nil {
+		return err
 	}
 
-	// Read the index(es).
-	var indexes []uint32
-	if err := survey.AskOne(&survey.Input{
-		Message: "Wallet index(es) (comma separated)",
-		Default: "0",
-	}, &s, survey.WithValidator(isCommaSeparatedUint32List)); err != nil {
+	// Figure out how the index(es) will be selected.
+	var selectMode string
+	if err = survey.AskOne(&survey.Select{
+		Message: "How should the wallet index(es) be selected",
+		Options: []string{selectIndexes, selectScan},
+	}, &selectMode); err != nil {
 		return err
 	}
-	for _, v := range strings.Split(s, ",") {
-		idx, _ := strconv.ParseUint(strings.TrimSpace(v), 10, 32)
-		indexes = append(indexes, uint32(idx))
-	}
 
-	// Do the derivation.
-	var (
-		seed  = bip39.MnemonicToSeed(nil, mnemonic)
-		infos []*walletInfo
-		err   error
-	)
-	switch algo {
-	case algoLedger:
-		infos, err = deriveLedger(seed, indexes)
-	case algoAdr0008:
-		infos, err = deriveAdr0008(seed, indexes)
-	case algoBitpie:
-		infos, err = deriveBitpie(seed, indexes)
-	default:
-		return fmt.Errorf("unknown algorithm")
-	}
-	if err != nil {
-		return err
+	var infos []*walletInfo
+	switch selectMode {
+	case selectIndexes:
+		// Read the index(es).
+		fmt.Printf(" Indexes can be given as `N`, ranges as `A-B`, or stepped ranges\n")
+		fmt.Printf(" as `A-B/STEP`, each optionally labeled with `=LABEL`\n")
+		fmt.Printf(" (eg: `0=cold,10-19=ops,100-200/10`).\n")
+		var s string
+		if err = survey.AskOne(&survey.Input{
+			Message: "Wallet index(es) (comma separated)",
+			Default: "0",
+		}, &s, survey.WithValidator(isIndexList)); err != nil {
+			return err
+		}
+		specs, _ := parseIndexes(s)
+		indexes := make([]uint32, 0, len(specs))
+		for _, spec := range specs {
+			indexes = append(indexes, spec.index)
+		}
+
+		// Do the derivation.
+		if infos, err = deriveFn(seed, indexes); err != nil {
+			return err
+		}
+		for i, spec := range specs {
+			infos[i].label = spec.label
+		}
+	case selectScan:
+		if infos, err = doScan(deriveFn, seed); err != nil {
+			return err
+		}
 	}
 	for _, v := range infos {
-		fmt.Printf(" Index[%d]: %s\n", v.index, v.address)
+		fmt.Printf(" Index[%d]: %s%s%s\n", v.index, v.address, v.labelString(), v.balanceString())
+	}
+
+	// Figure out if the user wants a paper wallet.
+	if err = doPaperWallet(algo, infos); err != nil {
+		return err
 	}
 
 	// Figure out if the user wants to write out the keys
@@ -159,6 +248,7 @@ func doInteractive() error {
 	if err != nil {
 		wd = "."
 	}
+	var s string
 	if err = survey.AskOne(&survey.Input{
 		Message: "Output directory",
 		Default: filepath.Join(wd, "wallet-export-"+time.Now().Format("2006-01-02")),
@@ -171,8 +261,8 @@ func doInteractive() error {
 
 	// Write out each wallet to disk.
 	for _, info := range infos {
-		fn := fmt.Sprintf("%s.private.pem", info.address)
-		b, err := encodeEd25519PrivateToPEMBuf(info.privateKey)
+		fn := info.fileName()
+		b, err := encodePrivateToPEMBuf(info.privateKey, info.pemHeaders(algo))
 		if err != nil {
 			return fmt.Errorf("failed to encode private key to PEM: %w", err)
 		}
@@ -181,114 +271,80 @@ func doInteractive() error {
 		}
 		fmt.Printf(" Index[%d]: %s - done\n", info.index, fn)
 	}
+	if err = writeManifest(filepath.Join(s, manifestFileName), algo, infos); err != nil {
+		return err
+	}
+	fmt.Printf(" Manifest: %s - done\n", manifestFileName)
+
+	// Make sure that the exported keys actually work.
+	if err = doProofOfControl(s, infos); err != nil {
+		return err
+	}
 
 	fmt.Printf("Done writing wallet keys to disk, goodbye.\n")
 
 	return nil
 }
 
-func deriveLedger(seed []byte, indexes []uint32) ([]*walletInfo, error) {
-	root, err := bip32.NewLedgerRoot(seed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to derive BIP32-Ed25519 root: %w", err)
+type deriveFunc func([]byte, []uint32) ([]*walletInfo, error)
+
+func getDeriveFn(algo string) (deriveFunc, error) {
+	var opts []wallet.Option
+	if tracer != nil {
+		opts = append(opts, wallet.WithTracer(tracer))
 	}
-	wallet, err := root.DerivePath("44'/474'/0'/0'")
+	d, err := wallet.New(wallet.Scheme(algo), opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to derive BIP32-Ed25519 wallet base: %w", err)
+		return nil, fmt.Errorf("unknown algorithm")
 	}
 
-	infos := make([]*walletInfo, 0, len(indexes))
-	for _, index := range indexes {
-		child, err := wallet.DeriveChild(index + bip32.HardenedIndexOffset)
-		if err != nil {
-			return nil, fmt.Errorf("failed to derive key for index %d: %w", index, err)
-		}
-		privateKey := child.GetLedgerPrivateKey()
-		address, err := address.FromPublicKey(privateKey.Public())
+	return func(seed []byte, indexes []uint32) ([]*walletInfo, error) {
+		keys, err := wallet.DeriveAccounts(d, seed, indexes)
 		if err != nil {
-			return nil, fmt.Errorf("failed to derive address for index %d: %w", index, err)
+			return nil, err
 		}
-		infos = append(infos, &walletInfo{
-			index:      index,
-			privateKey: privateKey,
-			address:    address,
-		})
-	}
 
-	return infos, nil
+		infos := make([]*walletInfo, 0, len(keys))
+		for i, key := range keys {
+			infos = append(infos, &walletInfo{
+				index:      indexes[i],
+				path:       key.Path,
+				privateKey: key.PrivateKey,
+				address:    key.Address,
+			})
+		}
+		return infos, nil
+	}, nil
 }
 
-func deriveAdr0008(seed []byte, indexes []uint32) ([]*walletInfo, error) {
-	// Derive the master secret.
-	secret, chainCode, err := slip10.NewMasterKey(seed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to derive SLIP-10 master key: %w", err)
-	}
-
-	// All wallets are in the path `//m/44'/474'/index'`, so descent to
-	// the common sub-root.
-	for _, index := range []uint32{44, 474} {
-		secret, chainCode, err = slip10.NewChildKey(secret, chainCode, index+bip32.HardenedIndexOffset)
-		if err != nil {
-			return nil, fmt.Errorf("failed to derive SLIP-10 sub-key (%d): %w", index, err)
-		}
-	}
-	infos := make([]*walletInfo, 0, len(indexes))
-	for _, index := range indexes {
-		child, _, err := slip10.NewChildKey(secret, chainCode, index+bip32.HardenedIndexOffset)
-		if err != nil {
-			return nil, fmt.Errorf("failed to derive key for index %d: %w", index, err)
-		}
-		privateKey := ed25519.NewKeyFromSeed(child[:])
-		address, err := address.FromPublicKey(privateKey.Public())
-		if err != nil {
-			return nil, fmt.Errorf("failed to derive address for index %d: %w", index, err)
-		}
-		infos = append(infos, &walletInfo{
-			index:      index,
-			privateKey: privateKey,
-			address:    address,
-		})
-	}
+type walletInfo struct {
+	index      uint32
+	label      string
+	path       string
+	privateKey crypto.Signer
+	address    string
 
-	return infos, nil
+	account *balance.Account
 }
 
-func deriveBitpie(seed []byte, indexes []uint32) ([]*walletInfo, error) {
-	root, err := bip32.NewBitpieRoot(seed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to derive Bitpie root: %w", err)
+func (info *walletInfo) labelString() string {
+	if info.label == "" {
+		return ""
 	}
-	wallet, err := root.DerivePath("0")
-	if err != nil {
-		return nil, fmt.Errorf("failed to derive Bitpie wallet base: %w", err)
-	}
-
-	infos := make([]*walletInfo, 0, len(indexes))
-	for _, index := range indexes {
-		child, err := wallet.DeriveChild(index)
-		if err != nil {
-			return nil, fmt.Errorf("failed to derive key for index %d: %w", index, err)
-		}
-		privateKey := child.GetBitpiePrivateKey()
-		address, err := address.FromPublicKey(privateKey.Public())
-		if err != nil {
-			return nil, fmt.Errorf("failed to derive address for index %d: %w", index, err)
-		}
-		infos = append(infos, &walletInfo{
-			index:      index,
-			privateKey: privateKey,
-			address:    address,
-		})
-	}
-
-	return infos, nil
+	return fmt.Sprintf(" [%s]", info.label)
 }
 
-type walletInfo struct {
-	index      uint32
-	privateKey ed25519.PrivateKey
-	address    string
+func (info *walletInfo) balanceString() string {
+	if info.account == nil {
+		return ""
+	}
+	return fmt.Sprintf(
+		" (general: %s, escrow: %s, debonding: %s, nonce: %d)",
+		info.account.General,
+		info.account.Escrow,
+		info.account.Debonding,
+		info.account.Nonce,
+	)
 }
 
 func isMnemonicLength(val interface{}) error {
@@ -303,34 +359,37 @@ func isMnemonicLength(val interface{}) error {
 	return nil
 }
 
-func isCommaSeparatedUint32List(val interface{}) error {
-	s := val.(string)
-	spl := strings.Split(s, ",")
-	for _, v := range spl {
-		v = strings.TrimSpace(v)
-		i, err := strconv.ParseUint(v, 10, 32)
-		if err != nil {
-			return fmt.Errorf("invalid index: '%s'", v)
-		}
-		if i > uint64(maxAccountKeyNumber) {
-			return fmt.Errorf("invalid index (out of range): '%s'", v)
-		}
-	}
-	return nil
-}
-
 func isMnemonicWord(val interface{}) error {
 	s := val.(string)
 	_, err := bip39.ExpandWord(s)
 	return err
 }
 
-func encodeEd25519PrivateToPEMBuf(k ed25519.PrivateKey) ([]byte, error) {
-	blk := &pem.Block{
-		Type:  "ED25519 PRIVATE KEY",
-		Bytes: k[:],
+func encodePrivateToPEMBuf(k crypto.Signer, headers map[string]string) ([]byte, error) {
+	var blk *pem.Block
+	switch kk := k.(type) {
+	case ed25519.PrivateKey:
+		blk = &pem.Block{
+			Type:  "ED25519 PRIVATE KEY",
+			Bytes: kk[:],
+		}
+	case bip32.ExtendedPrivateKey:
+		// There is no seed, so this can't be in the format that
+		// oasis-core expects.  kL || kR, followed by the public key.
+		pk, err := kk.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		blk = &pem.Block{
+			Type:  "ED25519 EXTENDED PRIVATE KEY",
+			Bytes: append(append([]byte{}, kk[:]...), pk[:]...),
+		}
+	default:
+		return nil, fmt.Errorf("unsupported private key type: %T", k)
 	}
 
+	blk.Headers = headers
+
 	var buf bytes.Buffer
 	if err := pem.Encode(&buf, blk); err != nil {
 		return nil, err
@@ -338,3 +397,10 @@ func encodeEd25519PrivateToPEMBuf(k ed25519.PrivateKey) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+func init() {
+	flag.BoolVar(&explain, "explain", false, "print intermediate derivation state, including chain codes (SENSITIVE, treat the output as secret)")
+	flag.BoolVar(&allowOnline, "allow-online", false, "allow running on a machine that appears to be online (DANGEROUS)")
+	flag.BoolVar(&requireLiveMedium, "require-live-medium", false, "require running from a read-only (live) medium")
+	flag.StringVar(&shard, "shard", "", "only search shard `i/n` of the recovery candidates (eg: 1/4)")
+}
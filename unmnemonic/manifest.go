@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+)
+
+const (
+	manifestFileName = "manifest.json"
+	manifestVersion  = 1
+)
+
+type manifest struct {
+	Version  int                `json:"version"`
+	Created  time.Time          `json:"created"`
+	Scheme   string             `json:"scheme"`
+	Accounts []*manifestAccount `json:"accounts"`
+}
+
+type manifestAccount struct {
+	Index     uint32          `json:"index"`
+	Label     string          `json:"label,omitempty"`
+	Path      string          `json:"path"`
+	Address   string          `json:"address"`
+	PublicKey publicKeyBase64 `json:"public_key"`
+	File      string          `json:"file"`
+}
+
+// publicKeyBase64 is an Ed25519 public key, in the standard (padded)
+// base64 encoding used for public keys by oasis-core (eg: in entity and
+// node descriptors).
+type publicKeyBase64 string
+
+func newPublicKeyBase64(pk ed25519.PublicKey) publicKeyBase64 {
+	return publicKeyBase64(base64.StdEncoding.EncodeToString(pk))
+}
+
+// fileName returns the name of the file the wallet's private key is
+// written to.
+func (info *walletInfo) fileName() string {
+	if info.label == "" {
+		return fmt.Sprintf("%s.private.pem", info.address)
+	}
+	return fmt.Sprintf("%s-%s.private.pem", info.label, info.address)
+}
+
+// pemHeaders returns the derivation metadata included in the wallet's
+// private key PEM file.
+func (info *walletInfo) pemHeaders(algo string) map[string]string {
+	headers := map[string]string{
+		"Scheme":  algo,
+		"Path":    info.path,
+		"Index":   strconv.FormatUint(uint64(info.index), 10),
+		"Address": info.address,
+	}
+	if info.label != "" {
+		headers["Label"] = info.label
+	}
+	return headers
+}
+
+func writeManifest(fn, algo string, infos []*walletInfo) error {
+	m := manifest{
+		Version: manifestVersion,
+		Created: time.Now().UTC(),
+		Scheme:  algo,
+	}
+	for _, info := range infos {
+		pk := info.privateKey.Public().(ed25519.PublicKey)
+		m.Accounts = append(m.Accounts, &manifestAccount{
+			Index:     info.index,
+			Label:     info.label,
+			Path:      info.path,
+			Address:   info.address,
+			PublicKey: newPublicKeyBase64(pk),
+			File:      info.fileName(),
+		})
+	}
+
+	b, err := json.MarshalIndent(&m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize manifest: %w", err)
+	}
+	if err = os.WriteFile(fn, append(b, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/oasisprotocol/tools/unmnemonic/internal/paperwallet"
+)
+
+func doPaperWallet(algo string, infos []*walletInfo) error {
+	entries := make([]*paperwallet.Entry, 0, len(infos))
+	for _, info := range infos {
+		entries = append(entries, &paperwallet.Entry{
+			Index:   info.index,
+			Scheme:  algo,
+			Address: info.address,
+		})
+	}
+
+	// Figure out if the user wants to see the addresses as QR codes.
+	var ok bool
+	if err := survey.AskOne(&survey.Confirm{
+		Message: "Display the addresses as QR codes",
+	}, &ok); err != nil {
+		return err
+	}
+	if ok {
+		for _, e := range entries {
+			if err := paperwallet.RenderTerminal(os.Stdout, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Figure out if the user wants a printable sheet.
+	if err := survey.AskOne(&survey.Confirm{
+		Message: "Write a printable paper wallet sheet (SVG)",
+	}, &ok); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	if err := survey.AskOne(&survey.Confirm{
+		Message: "Include passphrase encrypted private keys on the sheet",
+	}, &ok); err != nil {
+		return err
+	}
+	if ok {
+		passphrase, err := askNewPassphrase()
+		if err != nil {
+			return err
+		}
+		for i, info := range infos {
+			b, err := encodePrivateToPEMBuf(info.privateKey, nil)
+			if err != nil {
+				return fmt.Errorf("failed to encode private key to PEM: %w", err)
+			}
+			if entries[i].EncryptedKey, err = paperwallet.EncryptKey(b, passphrase); err != nil {
+				return err
+			}
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		wd = "."
+	}
+	var fn string
+	if err = survey.AskOne(&survey.Input{
+		Message: "Paper wallet file",
+		Default: filepath.Join(wd, "paper-wallet-"+time.Now().Format("2006-01-02")+".svg"),
+	}, &fn); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(fn, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create paper wallet file: %w", err)
+	}
+	defer f.Close()
+
+	title := fmt.Sprintf("Oasis paper wallet (%s) - %s", algo, time.Now().Format("2006-01-02"))
+	if err = paperwallet.WriteSVG(f, title, entries); err != nil {
+		return fmt.Errorf("failed to write paper wallet: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("failed to write paper wallet: %w", err)
+	}
+
+	fmt.Printf(" Paper wallet written to: %s\n", fn)
+
+	return nil
+}
+
+func askNewPassphrase() (string, error) {
+	for {
+		var passphrase, confirm string
+		if err := survey.AskOne(&survey.Password{
+			Message: "Encryption passphrase",
+		}, &passphrase, survey.WithValidator(survey.Required)); err != nil {
+			return "", err
+		}
+		if err := survey.AskOne(&survey.Password{
+			Message: "Confirm encryption passphrase",
+		}, &confirm); err != nil {
+			return "", err
+		}
+		if passphrase == confirm {
+			return passphrase, nil
+		}
+		fmt.Printf(" Passphrases do not match\n")
+	}
+}
@@ -0,0 +1,62 @@
+// Package address implements the v0 staking account address derivation.
+package address
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/bech32"
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+)
+
+const (
+	addrHRP     = "oasis"
+	addrContext = "oasis-core/address: staking"
+	addrVersion = 0
+	addrSize    = 21
+)
+
+// FromPublicKey returns the Oasis v0 staking address corresponding to the
+// provided Ed25519 public key.
+func FromPublicKey(pk crypto.PublicKey) (string, error) {
+	edPk, ok := pk.(ed25519.PublicKey)
+	if !ok || len(edPk) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("address: invalid public key")
+	}
+
+	var preimage [len(addrContext) + 1 + ed25519.PublicKeySize]byte
+	copy(preimage[:], addrContext)
+	preimage[len(addrContext)] = addrVersion
+	copy(preimage[len(addrContext)+1:], edPk)
+	digest := sha512.Sum512_256(preimage[:])
+
+	var addr [addrSize]byte
+	addr[0] = addrVersion
+	copy(addr[1:], digest[:addrSize-1])
+
+	return encodeBech32(&addr), nil
+}
+
+// Decode returns the raw (binary) form of a bech32 encoded Oasis v0
+// staking address.
+func Decode(addr string) ([]byte, error) {
+	hrp, data, err := bech32.Decode(addr)
+	if err != nil {
+		return nil, fmt.Errorf("address: failed to decode bech32: %w", err)
+	}
+	if hrp != addrHRP {
+		return nil, fmt.Errorf("address: invalid HRP: '%s'", hrp)
+	}
+	raw, err := bech32.ConvertBits(data, 5, 8, false)
+	if err != nil {
+		return nil, fmt.Errorf("address: failed to convert bits from bech32: %w", err)
+	}
+	if l := len(raw); l != addrSize {
+		return nil, fmt.Errorf("address: invalid length: %d", l)
+	}
+	if raw[0] != addrVersion {
+		return nil, fmt.Errorf("address: invalid version: %d", raw[0])
+	}
+	return raw, nil
+}
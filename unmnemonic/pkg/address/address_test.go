@@ -0,0 +1,82 @@
+package address
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/btcsuite/btcutil/bech32"
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+)
+
+func TestFromPublicKey(t *testing.T) {
+	pk := ed25519.PublicKey{
+		0xba, 0xda, 0xdd, 0x1e, 0x55, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	}
+
+	addr, err := FromPublicKey(pk)
+	if err != nil {
+		t.Fatalf("FromPublicKey: %v", err)
+	}
+
+	const expectedAddr = "oasis1qryqqccycvckcxp453tflalujvlf78xymcdqw4vz"
+	if addr != expectedAddr {
+		t.Fatalf("FromPublicKey(pk): expected '%s', got '%s'", expectedAddr, addr)
+	}
+
+	raw, err := Decode(addr)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if l := len(raw); l != addrSize || raw[0] != addrVersion {
+		t.Fatalf("Decode(addr): unexpected raw address: %02x", raw)
+	}
+	if _, err = Decode("oasis1qryqqccycvckcxp453tflalujvlf78xymcdqw4vy"); err == nil {
+		t.Fatalf("Decode: failed to reject corrupted address")
+	}
+}
+
+func TestFromPublicKeyBech32(t *testing.T) {
+	// Cross-check the allocation-free bech32 encoding against the
+	// generic implementation.
+	for i := 0; i < 1000; i++ {
+		pk := make(ed25519.PublicKey, ed25519.PublicKeySize)
+		if _, err := rand.Read(pk); err != nil {
+			t.Fatalf("rand.Read: %v", err)
+		}
+
+		h := sha512.New512_256()
+		_, _ = h.Write([]byte(addrContext))
+		_, _ = h.Write([]byte{addrVersion})
+		_, _ = h.Write(pk)
+		raw := append([]byte{addrVersion}, h.Sum(nil)[:addrSize-1]...)
+		converted, err := bech32.ConvertBits(raw, 8, 5, true)
+		if err != nil {
+			t.Fatalf("bech32.ConvertBits: %v", err)
+		}
+		expected, err := bech32.Encode(addrHRP, converted)
+		if err != nil {
+			t.Fatalf("bech32.Encode: %v", err)
+		}
+
+		addr, err := FromPublicKey(pk)
+		if err != nil {
+			t.Fatalf("FromPublicKey: %v", err)
+		}
+		if addr != expected {
+			t.Fatalf("FromPublicKey(%x): expected '%s', got '%s'", pk, expected, addr)
+		}
+	}
+}
+
+func BenchmarkFromPublicKey(b *testing.B) {
+	pk := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := FromPublicKey(pk); err != nil {
+			b.Fatalf("FromPublicKey: %v", err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package address
+
+const (
+	bech32Charset      = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32ChecksumSize = 6
+
+	// addrDataSize is the size of an address, in 5-bit groups.
+	addrDataSize = (addrSize*8 + 4) / 5
+	// addrEncodedSize is the size of a bech32 encoded address.
+	addrEncodedSize = len(addrHRP) + 1 + addrDataSize + bech32ChecksumSize
+)
+
+var bech32Generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+func bech32PolymodStep(chk uint32, v byte) uint32 {
+	b := chk >> 25
+	chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+	for i := 0; i < 5; i++ {
+		if (b>>uint(i))&1 == 1 {
+			chk ^= bech32Generator[i]
+		}
+	}
+	return chk
+}
+
+// encodeBech32 encodes a raw address as bech32 (BIP-173), with the only
+// allocation being the returned string.
+func encodeBech32(addr *[addrSize]byte) string {
+	// Regroup the 8-bit bytes into 5-bit groups, padding the end.
+	var (
+		data [addrDataSize]byte
+		acc  uint32
+		bits uint
+		n    int
+	)
+	for _, b := range addr {
+		acc = acc<<8 | uint32(b)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			data[n] = byte(acc>>bits) & 0x1f
+			n++
+		}
+	}
+	if bits > 0 {
+		data[n] = byte(acc<<(5-bits)) & 0x1f
+	}
+
+	// Checksum the expanded HRP, the data, and 6 zero groups.
+	chk := uint32(1)
+	for i := 0; i < len(addrHRP); i++ {
+		chk = bech32PolymodStep(chk, addrHRP[i]>>5)
+	}
+	chk = bech32PolymodStep(chk, 0)
+	for i := 0; i < len(addrHRP); i++ {
+		chk = bech32PolymodStep(chk, addrHRP[i]&0x1f)
+	}
+	for _, v := range data {
+		chk = bech32PolymodStep(chk, v)
+	}
+	for i := 0; i < bech32ChecksumSize; i++ {
+		chk = bech32PolymodStep(chk, 0)
+	}
+	chk ^= 1
+
+	var out [addrEncodedSize]byte
+	n = copy(out[:], addrHRP)
+	out[n] = '1'
+	n++
+	for _, v := range data {
+		out[n] = bech32Charset[v]
+		n++
+	}
+	for i := 0; i < bech32ChecksumSize; i++ {
+		out[n] = bech32Charset[(chk>>uint(5*(bech32ChecksumSize-1-i)))&0x1f]
+		n++
+	}
+
+	return string(out[:])
+}
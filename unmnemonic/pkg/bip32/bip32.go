@@ -16,7 +16,7 @@ import (
 	"github.com/oasisprotocol/curve25519-voi/curve/scalar"
 	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
 
-	"github.com/oasisprotocol/tools/unmnemonic/internal/slip10"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/slip10"
 )
 
 const (
@@ -32,9 +32,40 @@ var (
 	// is a multiple of the scalar group order.
 	ErrDivisibleByBaseOrder = errors.New("bip32: kL divisible by basepoint order")
 
+	// ErrInvalidMasterKey is the error returned when the master key
+	// derived from a seed is discarded (the third highest bit of kL is
+	// set).
+	ErrInvalidMasterKey = errors.New("bip32: invalid k")
+
 	scalarZero scalar.Scalar
 )
 
+// Tracer receives the intermediate derivation state, for debugging
+// mismatches against other implementations.
+//
+// The chain codes passed to Step are SENSITIVE: a chain code combined
+// with any child private key compromises the sibling keys, and combined
+// with the public key enables non-hardened child derivation.
+type Tracer interface {
+	// Step is called with the index, chain code and public key of each
+	// derived child.
+	Step(scheme string, index uint32, chainCode []byte, publicKey ed25519.PublicKey)
+
+	// Printf is called with any other intermediate state.
+	Printf(format string, a ...interface{})
+}
+
+// Option is a root node option.
+type Option func(*Node)
+
+// WithTracer traces the derivation of the root node, and of every node
+// derived from it, to t.
+func WithTracer(t Tracer) Option {
+	return func(n *Node) {
+		n.tracer = t
+	}
+}
+
 // Node is a HKD derivation node.
 type Node struct {
 	kL [32]byte
@@ -43,6 +74,14 @@ type Node struct {
 
 	isRoot   bool
 	isBitpie bool
+
+	tracer Tracer
+}
+
+func (n *Node) applyOptions(opts []Option) {
+	for _, opt := range opts {
+		opt(n)
+	}
 }
 
 // GetLedgerPrivateKey returns the Oasis network private key associated
@@ -56,9 +95,9 @@ func (n *Node) GetLedgerPrivateKey() ed25519.PrivateKey {
 
 // GetExtendedPrivateKey returns the Oasis network private key associated
 // with a node, in the BIP-Ed25519 extended format.
-func (n *Node) GetExtendedPrivateKey() []byte {
-	var r []byte
-	r = append([]byte{}, n.kL[:]...)
+func (n *Node) GetExtendedPrivateKey() ExtendedPrivateKey {
+	r := make([]byte, 0, ExtendedPrivateKeySize)
+	r = append(r, n.kL[:]...)
 	r = append(r, n.kR[:]...)
 	return r
 }
@@ -130,7 +169,9 @@ func (n *Node) DeriveChild(idx uint32) (*Node, error) {
 	}
 	_, _ = cMac.Write(iBytes[:])
 	c := cMac.Sum(nil)
-	var childNode Node
+	childNode := Node{
+		tracer: n.tracer,
+	}
 	copy(childNode.c[:], c[32:]) // where the output of F is truncated to the right 32 bytes.
 
 	// ZL, ZR = Z[:28], Z[32:]
@@ -170,6 +211,14 @@ func (n *Node) DeriveChild(idx uint32) (*Node, error) {
 		return nil, ErrDivisibleByBaseOrder
 	}
 
+	if n.tracer != nil {
+		pk, err := ScalarToPublicKey(childNode.kL[:])
+		if err != nil {
+			return nil, err
+		}
+		n.tracer.Step("bip32-ed25519", idx, childNode.c[:], pk)
+	}
+
 	return &childNode, nil
 }
 
@@ -179,6 +228,27 @@ func (n *Node) DerivePath(path string) (*Node, error) {
 		return nil, fmt.Errorf("bip32: base node is not the root")
 	}
 
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := n
+	for _, idx := range indices {
+		ret, err = ret.DeriveChild(idx)
+		if err != nil {
+			return nil, fmt.Errorf("bip32: failed to derive child %d': %w", idx, err)
+		}
+	}
+
+	return ret, nil
+}
+
+// ParsePath parses a path (eg: `44'/474'/0'`) relative to the root node,
+// into derivation indexes.  A leading `m/` is permitted and ignored.
+func ParsePath(path string) ([]uint32, error) {
+	path = strings.TrimPrefix(path, "m/")
+
 	splitPath := strings.Split(path, "/")
 	indices := make([]uint32, 0, len(splitPath))
 	for _, pathEntry := range splitPath {
@@ -203,21 +273,12 @@ func (n *Node) DerivePath(path string) (*Node, error) {
 		return nil, fmt.Errorf("bip32: path length over permitted maximum")
 	}
 
-	var err error
-	ret := n
-	for _, idx := range indices {
-		ret, err = ret.DeriveChild(idx)
-		if err != nil {
-			return nil, fmt.Errorf("bip32: failed to derive child %d': %w", idx, err)
-		}
-	}
-
-	return ret, nil
+	return indices, nil
 }
 
 // NewLedgerRoot returns the root (master) node, corresponding to the
 // provided seed, using the fucked up Ledger BIP32-Ed25519 variant.
-func NewLedgerRoot(seed []byte) (*Node, error) {
+func NewLedgerRoot(seed []byte, opts ...Option) (*Node, error) {
 	sTmp := append([]byte{}, seed...) // Copy
 
 	var (
@@ -242,7 +303,9 @@ func NewLedgerRoot(seed []byte) (*Node, error) {
 
 	// BIP32-Ed25519: If the third highest bit of the last byte of kL is
 	// not zero, discard k.
+	var iters int
 	for {
+		iters++
 		var (
 			kL *slip10.Secret
 			kR *slip10.ChainCode
@@ -263,6 +326,11 @@ func NewLedgerRoot(seed []byte) (*Node, error) {
 		sTmp = append(sTmp, n.kR[:]...)
 	}
 
+	n.applyOptions(opts)
+	if n.tracer != nil {
+		n.tracer.Printf("bip32-ed25519: ledger root: master key derivation iterations=%d", iters)
+	}
+
 	// BIP32-Ed25519 requires that the scalar clamping is applied
 	// to kL (Master secret) as in vanilla Ed25519pure.
 	clampScalar(n.kL[:])
@@ -274,7 +342,7 @@ func NewLedgerRoot(seed []byte) (*Node, error) {
 
 // NewRoot returns the root (master) node, corresponding to the provided
 // seed.
-func NewRoot(seed []byte) (*Node, error) {
+func NewRoot(seed []byte, opts ...Option) (*Node, error) {
 	// k' = H512(k)
 	kPrime := sha512.Sum512(seed)
 
@@ -285,7 +353,7 @@ func NewRoot(seed []byte) (*Node, error) {
 	// If the third highest bit of the last byte of kL is not zero,
 	// discard k'.
 	if n.kL[31]&0x20 == 0x20 { // ~0b00100000
-		return nil, fmt.Errorf("bip32: invalid k")
+		return nil, ErrInvalidMasterKey
 	}
 
 	// BIP32-Ed25519 requires that the scalar clamping is applied
@@ -300,6 +368,28 @@ func NewRoot(seed []byte) (*Node, error) {
 	copy(n.c[:], c)
 
 	n.isRoot = true
+	n.applyOptions(opts)
+
+	return &n, nil
+}
+
+// NewRootFromExtendedKey returns a root node, corresponding to the provided
+// extended private key (kL || kR) and chain code.  Unlike NewRoot, kL is
+// used as-is, so that nodes from other implementations can be imported.
+func NewRootFromExtendedKey(k ExtendedPrivateKey, chainCode []byte, opts ...Option) (*Node, error) {
+	if l := len(k); l != ExtendedPrivateKeySize {
+		return nil, fmt.Errorf("bip32: bad extended private key length: %d", l)
+	}
+	if l := len(chainCode); l != 32 {
+		return nil, fmt.Errorf("bip32: bad chain code length: %d", l)
+	}
+
+	var n Node
+	copy(n.kL[:], k[:32])
+	copy(n.kR[:], k[32:])
+	copy(n.c[:], chainCode)
+	n.isRoot = true
+	n.applyOptions(opts)
 
 	return &n, nil
 }
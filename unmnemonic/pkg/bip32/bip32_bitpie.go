@@ -31,6 +31,7 @@ func (n *Node) deriveBitpieChild(idx uint32) (*Node, error) {
 
 	childNode := &Node{
 		isBitpie: true,
+		tracer:   n.tracer,
 	}
 	copy(childNode.kL[:], h[:32])
 	copy(childNode.c[:], h[32:])
@@ -53,6 +54,14 @@ func (n *Node) deriveBitpieChild(idx uint32) (*Node, error) {
 		return nil, fmt.Errorf("bip32: bitpie child derivation overflows")
 	}
 
+	if n.tracer != nil {
+		pk, err := bitpieSeedToPublicKey(childNode.kL[:])
+		if err != nil {
+			return nil, err
+		}
+		n.tracer.Step("bitpie", idx, childNode.c[:], pk)
+	}
+
 	return childNode, nil
 }
 
@@ -75,7 +84,7 @@ func bitpieSeedToPublicKey(seed []byte) (ed25519.PublicKey, error) {
 	return priv.Public().(ed25519.PublicKey), nil
 }
 
-func NewBitpieRoot(seed []byte) (*Node, error) {
+func NewBitpieRoot(seed []byte, opts ...Option) (*Node, error) {
 	masterKey, _, err := newBitpieMasterKey(seed)
 	if err != nil {
 		return nil, err
@@ -88,6 +97,7 @@ func NewBitpieRoot(seed []byte) (*Node, error) {
 		isBitpie: true,
 	}
 	copy(rootNode.kL[:], masterKey)
+	rootNode.applyOptions(opts)
 
 	return rootNode, nil
 }
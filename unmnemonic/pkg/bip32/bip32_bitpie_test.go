@@ -7,8 +7,8 @@ import (
 
 	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
 
-	"github.com/oasisprotocol/tools/unmnemonic/internal/address"
-	"github.com/oasisprotocol/tools/unmnemonic/internal/bip39"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/address"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
 )
 
 func testKnownAnswerBitpie(t *testing.T) {
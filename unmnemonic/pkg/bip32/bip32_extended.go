@@ -0,0 +1,106 @@
+package bip32
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"fmt"
+	"io"
+
+	"github.com/oasisprotocol/curve25519-voi/curve"
+	"github.com/oasisprotocol/curve25519-voi/curve/scalar"
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+)
+
+// ExtendedPrivateKeySize is the size of a BIP32-Ed25519 extended private
+// key (kL || kR) in bytes.
+const ExtendedPrivateKeySize = 64
+
+// ExtendedPrivateKey is a BIP32-Ed25519 extended private key (kL || kR).
+//
+// Unlike a RFC 8032 private key, there is no seed, kL is used directly
+// as the secret scalar, and kR is used as the nonce prefix.  Signatures
+// produced with this are regular Ed25519 signatures, verifiable with
+// the corresponding public key.
+type ExtendedPrivateKey []byte
+
+// Public returns the public key corresponding to the extended private key,
+// or nil if the extended private key is malformed.
+func (k ExtendedPrivateKey) Public() crypto.PublicKey {
+	pk, err := k.PublicKey()
+	if err != nil {
+		return nil
+	}
+	return pk
+}
+
+// PublicKey returns the Ed25519 public key corresponding to the extended
+// private key.
+func (k ExtendedPrivateKey) PublicKey() (ed25519.PublicKey, error) {
+	if l := len(k); l != ExtendedPrivateKeySize {
+		return nil, fmt.Errorf("bip32: bad extended private key length: %d", l)
+	}
+	return ScalarToPublicKey(k[:32])
+}
+
+// Sign signs the message with the extended private key, in the manner of
+// Ed25519pure.  The rand argument is ignored, and opts.HashFunc() must
+// return 0 as pre-hashed messages are not supported.
+func (k ExtendedPrivateKey) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, fmt.Errorf("bip32: pre-hashed messages are not supported")
+	}
+	pk, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// r = H(kR || m)
+	var (
+		hashr [64]byte
+		r     scalar.Scalar
+	)
+	h := sha512.New()
+	_, _ = h.Write(k[32:])
+	_, _ = h.Write(message)
+	h.Sum(hashr[:0])
+	if _, err := r.SetBytesModOrderWide(hashr[:]); err != nil {
+		return nil, fmt.Errorf("bip32: failed to deserialize r scalar: %w", err)
+	}
+
+	// R = rB
+	var (
+		R           curve.EdwardsPoint
+		rCompressed curve.CompressedEdwardsY
+	)
+	rCompressed.SetEdwardsPoint(R.MulBasepoint(curve.ED25519_BASEPOINT_TABLE, &r))
+
+	// S = H(R,A,m)
+	var (
+		hram [64]byte
+		S    scalar.Scalar
+	)
+	h.Reset()
+	_, _ = h.Write(rCompressed[:])
+	_, _ = h.Write(pk[:])
+	_, _ = h.Write(message)
+	h.Sum(hram[:0])
+	if _, err := S.SetBytesModOrderWide(hram[:]); err != nil {
+		return nil, fmt.Errorf("bip32: failed to deserialize H(R,A,m) scalar: %w", err)
+	}
+
+	// S = (r + H(R,A,m)kL) mod L
+	var a scalar.Scalar
+	if _, err := a.SetBits(clampScalar(append([]byte{}, k[:32]...))); err != nil {
+		return nil, fmt.Errorf("bip32: failed to deserialize kL scalar: %w", err)
+	}
+	S.Mul(&S, &a)
+	S.Add(&S, &r)
+
+	sig := make([]byte, ed25519.SignatureSize)
+	copy(sig[:32], rCompressed[:])
+	if err := S.ToBytes(sig[32:]); err != nil {
+		return nil, fmt.Errorf("bip32: failed to serialize S scalar: %w", err)
+	}
+
+	return sig, nil
+}
@@ -0,0 +1,90 @@
+package bip32
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+)
+
+func TestExtendedPrivateKey(t *testing.T) {
+	t.Run("RFC8032", testExtendedPrivateKeyRFC8032)
+	t.Run("Derived", testExtendedPrivateKeyDerived)
+	t.Run("Malformed", testExtendedPrivateKeyMalformed)
+}
+
+func testExtendedPrivateKeyRFC8032(t *testing.T) {
+	// An RFC 8032 private key is just an extended private key with
+	// kL || kR = H512(seed) (and kL clamped), so signatures made with
+	// the expanded form must be identical.
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	expected := ed25519.NewKeyFromSeed(seed)
+
+	h := sha512.Sum512(seed)
+	clampScalar(h[:32])
+	k := ExtendedPrivateKey(h[:])
+
+	if pk := k.Public().(ed25519.PublicKey); !pk.Equal(expected.Public()) {
+		t.Fatalf("public key mismatch, expected %x, got %x", expected.Public(), pk)
+	}
+
+	msg := []byte("oasis extended key signing test")
+	sig, err := k.Sign(nil, msg, crypto.Hash(0))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if expectedSig := ed25519.Sign(expected, msg); !bytes.Equal(expectedSig, sig) {
+		t.Fatalf("signature mismatch, expected %x, got %x", expectedSig, sig)
+	}
+}
+
+func testExtendedPrivateKeyDerived(t *testing.T) {
+	n := Node{
+		kL:     mustUnhex(t, "80660d61ec16a6ca93e05e1738082dff22a422f00e95a5dfa9d91a74fab4725a"),
+		kR:     mustUnhex(t, "017255017df26d8ff29dbe315c838cd3837a311e611a9dd1c8c8a82a21ad2ec3"),
+		c:      mustUnhex(t, "14828443112319ee3ee64c82cda51c0f0df3c9550994bf70b4383d234e6e8ffd"),
+		isRoot: true,
+	}
+
+	child, err := n.DerivePath("1/2'")
+	if err != nil {
+		t.Fatalf("DerivePath(1/2'): %v", err)
+	}
+	k := child.GetExtendedPrivateKey()
+	if l := len(k); l != ExtendedPrivateKeySize {
+		t.Fatalf("unexpected extended private key length: %d", l)
+	}
+
+	msg := []byte("oasis extended key signing test")
+	sig, err := k.Sign(nil, msg, crypto.Hash(0))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if !ed25519.Verify(k.Public().(ed25519.PublicKey), msg, sig) {
+		t.Fatalf("signature failed to verify")
+	}
+
+	if _, err = k.Sign(nil, msg, crypto.SHA512); err == nil {
+		t.Fatalf("failed to reject pre-hashed message")
+	}
+}
+
+func testExtendedPrivateKeyMalformed(t *testing.T) {
+	for _, l := range []int{0, 32, ExtendedPrivateKeySize - 1, ExtendedPrivateKeySize + 1} {
+		k := ExtendedPrivateKey(make([]byte, l))
+		if pk := k.Public(); pk != nil {
+			t.Fatalf("Public(len %d): unexpected public key: %v", l, pk)
+		}
+		if _, err := k.PublicKey(); err == nil {
+			t.Fatalf("PublicKey(len %d): failed to reject malformed key", l)
+		}
+		if _, err := k.Sign(nil, []byte("msg"), crypto.Hash(0)); err == nil {
+			t.Fatalf("Sign(len %d): failed to reject malformed key", l)
+		}
+	}
+}
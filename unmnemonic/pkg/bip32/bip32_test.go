@@ -6,11 +6,12 @@ import (
 
 	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
 
-	"github.com/oasisprotocol/tools/unmnemonic/internal/bip39"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
 )
 
 func TestKnownAnswer(t *testing.T) {
 	t.Run("Paper", testKnownAnswerPaper)
+	t.Run("Paper/Imported", testKnownAnswerPaperImported)
 	t.Run("Ledger", testKnownAnswerLedger)
 	t.Run("Bitpie/Consistency", testKnownAnswerBitpieConsistency)
 	t.Run("Bitpie", testKnownAnswerBitpie)
@@ -42,6 +43,35 @@ func testKnownAnswerPaper(t *testing.T) {
 	debugDumpNode(t, "child-1", child)
 }
 
+func testKnownAnswerPaperImported(t *testing.T) {
+	kL := mustUnhex(t, "80660d61ec16a6ca93e05e1738082dff22a422f00e95a5dfa9d91a74fab4725a")
+	kR := mustUnhex(t, "017255017df26d8ff29dbe315c838cd3837a311e611a9dd1c8c8a82a21ad2ec3")
+	c := mustUnhex(t, "14828443112319ee3ee64c82cda51c0f0df3c9550994bf70b4383d234e6e8ffd")
+
+	n, err := NewRootFromExtendedKey(append(kL[:], kR[:]...), c[:])
+	if err != nil {
+		t.Fatalf("NewRootFromExtendedKey: %v", err)
+	}
+	child, err := n.DeriveChild(1)
+	if err != nil {
+		t.Fatalf("DeriveChild(1): %v", err)
+	}
+	assertNodeEqualsHex(
+		t,
+		"c02210e035578f15b48ad54d90d59a88352d3160f36d0458b3e1583302b5725a",
+		"435748df6415038a8fe35c46779fea8554b747a9093a8f784cf079144fc00317",
+		"594479b4ed8519d7c4378a9d7c782029f61d4ec107900b8dfb70c7d609ad5a16",
+		child,
+	)
+
+	if _, err = NewRootFromExtendedKey(kL[:], c[:]); err == nil {
+		t.Fatalf("NewRootFromExtendedKey: failed to reject short key")
+	}
+	if _, err = NewRootFromExtendedKey(append(kL[:], kR[:]...), c[:16]); err == nil {
+		t.Fatalf("NewRootFromExtendedKey: failed to reject short chain code")
+	}
+}
+
 func testKnownAnswerLedger(t *testing.T) {
 	// All values taken from the python code I was told implements what
 	// a real ledger device will.
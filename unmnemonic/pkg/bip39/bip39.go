@@ -0,0 +1,176 @@
+// Package bip39 implements the non-generation portions of BIP-39
+// "Mnemonic code for generating deterministic keys".
+package bip39
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+)
+
+const (
+	expansionIters      = 2048
+	expansionSaltPrefix = "mnemonic"
+
+	wordBits      = 11
+	maxWordLength = 8
+	// maxPackedSize is the size of the largest (24 word) mnemonic's
+	// entropy and checksum, in bytes.
+	maxPackedSize = 24 * wordBits / 8
+)
+
+// GetEntropyBits returns the amount of entropy in a given number of words, in bits.
+func GetEntropyBits(l int) (int, error) {
+	switch l {
+	case 12:
+		return 128, nil
+	case 15:
+		return 160, nil
+	case 18:
+		return 192, nil
+	case 21:
+		return 224, nil
+	case 24:
+		return 256, nil
+	default:
+		return 0, fmt.Errorf("bip39: invalid mnemonic, unexpected number of words: %d", l)
+	}
+}
+
+// ValidateAndExpandMnemonic expands abbreviated 4-character prefixes to their
+// full words, validates the mnemonic for correctness, and returns the full
+// mnemonic suitable for seed derivation.
+func ValidateAndExpandMnemonic(raw []byte) ([]byte, error) {
+	expanded, _, err := decodeMnemonic(raw)
+	if err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// decodeMnemonic expands and validates a mnemonic, returning the full
+// mnemonic and the entropy that it encodes.
+func decodeMnemonic(raw []byte) ([]byte, []byte, error) {
+	nrWords := bytes.Count(raw, []byte(" ")) + 1
+	entropyBits, err := GetEntropyBits(nrWords)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Note: This is not anything resembling constant time.  Users would
+	// need to be out of their god damn minds to use this on a system
+	// connected to any network, so whatever.
+
+	var (
+		packed   [maxPackedSize]byte
+		bitOff   int
+		expanded = make([]byte, 0, nrWords*(maxWordLength+1))
+	)
+	for i := 0; i < nrWords; i++ {
+		prefix := raw
+		if j := bytes.IndexByte(raw, ' '); j >= 0 {
+			prefix, raw = raw[:j], raw[j+1:]
+		}
+
+		idx, ok := englishWordLUT[string(prefix)]
+		if !ok {
+			word, err := ExpandWord(string(prefix))
+			if err != nil {
+				return nil, nil, err
+			}
+			idx = englishWordLUT[word]
+		}
+
+		if i > 0 {
+			expanded = append(expanded, ' ')
+		}
+		expanded = append(expanded, englishWords[idx]...)
+
+		// Store the 11 bits corresponding to the word as well.
+		putBits(packed[:], bitOff, uint32(idx), wordBits)
+		bitOff += wordBits
+	}
+
+	// Use the accumulated bits to derive the initial entropy and
+	// checksum.  Nothing fancy, just the checksum concatenated to
+	// the entropy.
+	checksumBits := uint(entropyBits) / 32
+	entropyBytes := append([]byte{}, packed[:entropyBits/8]...)
+	checksum := packed[entropyBits/8] >> (8 - checksumBits)
+
+	// Validate the checksum, which is the first n-bits of the SHA256
+	// digest of the entropy.
+	entropyDigest := sha256.Sum256(entropyBytes)
+	derivedChecksum := entropyDigest[0] >> (8 - checksumBits)
+	if derivedChecksum != checksum {
+		return nil, nil, fmt.Errorf("bip39: checksum mismatch")
+	}
+
+	// Checksum ok, return the possibly expanded mnemonic.
+	return expanded, entropyBytes, nil
+}
+
+// MnemonicToEntropy returns the entropy encoded by a mnemonic, after
+// expanding and validating it like ValidateAndExpandMnemonic.
+func MnemonicToEntropy(raw []byte) ([]byte, error) {
+	_, entropy, err := decodeMnemonic(raw)
+	if err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// EntropyToMnemonic returns the mnemonic that encodes the provided entropy.
+func EntropyToMnemonic(entropy []byte) ([]byte, error) {
+	entropyBits := len(entropy) * 8
+	if entropyBits < 128 || entropyBits > 256 || entropyBits%32 != 0 {
+		return nil, fmt.Errorf("bip39: invalid entropy, unexpected number of bits: %d", entropyBits)
+	}
+
+	// Append the checksum, which is the first n-bits of the SHA256
+	// digest of the entropy.
+	var packed [maxPackedSize]byte
+	copy(packed[:], entropy)
+	entropyDigest := sha256.Sum256(entropy)
+	packed[len(entropy)] = entropyDigest[0]
+
+	// Split the bits into 11-bit word indexes.
+	nrWords := (entropyBits + entropyBits/32) / wordBits
+	mnemonic := make([]byte, 0, nrWords*(maxWordLength+1))
+	for i := 0; i < nrWords; i++ {
+		if i > 0 {
+			mnemonic = append(mnemonic, ' ')
+		}
+		mnemonic = append(mnemonic, englishWords[getBits(packed[:], i*wordBits, wordBits)]...)
+	}
+
+	return mnemonic, nil
+}
+
+// putBits writes the n least significant bits of v to b, starting at
+// bit offset off (MSB first).
+func putBits(b []byte, off int, v uint32, n int) {
+	for i := n - 1; i >= 0; i, off = i-1, off+1 {
+		if v>>uint(i)&1 == 1 {
+			b[off/8] |= 0x80 >> uint(off%8)
+		}
+	}
+}
+
+// getBits reads n bits from b, starting at bit offset off (MSB first).
+func getBits(b []byte, off, n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i, off = i+1, off+1 {
+		v = v<<1 | uint32(b[off/8]>>(7-uint(off%8))&1)
+	}
+	return v
+}
+
+// MnemonicToSeed converts from a mnemonic to a seed.  Note that the mnemonic
+// should be validated and fixed-up with ValidateAndExpandMnemonic prior to
+// being converted to a seed.
+func MnemonicToSeed(passphrase, mnemonic []byte) []byte {
+	salt := make([]byte, 0, len(expansionSaltPrefix)+len(passphrase))
+	salt = append(append(salt, expansionSaltPrefix...), passphrase...)
+	return pbkdf2SHA512(mnemonic, salt, expansionIters)
+}
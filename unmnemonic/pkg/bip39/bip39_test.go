@@ -0,0 +1,181 @@
+package bip39
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const passphraseTrezor = "TREZOR"
+
+func TestShortMnemonic(t *testing.T) {
+	if _, err := ValidateAndExpandMnemonic([]byte("abandon")); err == nil {
+		t.Fatalf("failed to reject undersized mnemonic")
+	} else {
+		t.Logf("undersized mnemonic: %v", err)
+	}
+}
+
+func TestBadChecksum(t *testing.T) {
+	if _, err := ValidateAndExpandMnemonic([]byte("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon")); err == nil {
+		t.Fatalf("failed to reject corrupted mnemonic")
+	} else {
+		t.Logf("corrupted mnemonic: %v", err)
+	}
+}
+
+func TestAbbreviation(t *testing.T) {
+	const expected = "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic bless"
+	expanded, err := ValidateAndExpandMnemonic([]byte("lett advi cage absu amou doct acou avoi LETT ADVI CAGE ABSU AMOU DOCT ACOU AVOI letter advice cage absurd amount doctor acoustic bless"))
+	if err != nil {
+		t.Fatalf("failed to expand menemonic with abbreviations: %v", err)
+	}
+	if !bytes.Equal(expanded, []byte(expected)) {
+		t.Fatalf("mnemonic mismatch: expected '%s', got '%s'", expected, expanded)
+	}
+}
+
+func TestBadEntropy(t *testing.T) {
+	for _, l := range []int{0, 12, 15, 17, 18, 33} {
+		if _, err := EntropyToMnemonic(make([]byte, l)); err == nil {
+			t.Fatalf("failed to reject %d byte entropy", l)
+		}
+	}
+}
+
+func TestVectors(t *testing.T) {
+	// Deserialize the test vectors.
+	rawVectors, err := os.ReadFile("testdata/bip39_vectors.json")
+	if err != nil {
+		t.Fatalf("failed to read test vectors: %v", err)
+	}
+	testVectors := make(map[string][][4]string)
+	if err = json.Unmarshal(rawVectors, &testVectors); err != nil {
+		t.Fatalf("failed to deserialize test vectors: %v", err)
+	}
+
+	// English motherfucker, do you speak it?
+	englishTestVectors := testVectors["english"]
+	if len(englishTestVectors) == 0 {
+		t.Fatalf("failed to find english test vectors")
+	}
+	for i, vector := range englishTestVectors {
+		t.Run(fmt.Sprintf("Vector %d", i), func(t *testing.T) {
+			// entropy, mnemonic, seed, xprv(?)
+			entropy, err := hex.DecodeString(vector[0])
+			if err != nil {
+				t.Fatalf("failed to deserialize entropy: %v", err)
+			}
+			mnemonic := []byte(vector[1])
+			seed, err := hex.DecodeString(vector[2])
+			if err != nil {
+				t.Fatalf("failed to deserialize seed: %v", err)
+			}
+
+			derivedMnemonic, err := ValidateAndExpandMnemonic(mnemonic)
+			if err != nil {
+				t.Fatalf("failed to validate/expand mnemonic: %v", err)
+			}
+			if !bytes.Equal(mnemonic, derivedMnemonic) {
+				t.Fatalf("mnemonic mismatch: expected '%s', got '%s'", mnemonic, derivedMnemonic)
+			}
+
+			derivedEntropy, err := MnemonicToEntropy(mnemonic)
+			if err != nil {
+				t.Fatalf("failed to convert mnemonic to entropy: %v", err)
+			}
+			if !bytes.Equal(entropy, derivedEntropy) {
+				t.Fatalf("entropy mismatch: expected %02x, got %02x", entropy, derivedEntropy)
+			}
+			if derivedMnemonic, err = EntropyToMnemonic(entropy); err != nil {
+				t.Fatalf("failed to convert entropy to mnemonic: %v", err)
+			}
+			if !bytes.Equal(mnemonic, derivedMnemonic) {
+				t.Fatalf("mnemonic mismatch (from entropy): expected '%s', got '%s'", mnemonic, derivedMnemonic)
+			}
+			derivedSeed := MnemonicToSeed([]byte(passphraseTrezor), derivedMnemonic)
+			if !bytes.Equal(seed, derivedSeed) {
+				t.Fatalf("seed mismatch: expected %02x, got %02x", seed, derivedSeed)
+			}
+		})
+	}
+}
+
+func TestPBKDF2(t *testing.T) {
+	// Cross-check the specialized PBKDF2 against the generic one,
+	// including with a password longer than the SHA-512 block size.
+	for _, password := range [][]byte{
+		[]byte(benchMnemonic),
+		[]byte("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"),
+		nil,
+	} {
+		for _, salt := range []string{"mnemonic", "mnemonic" + passphraseTrezor} {
+			expected := pbkdf2.Key(password, []byte(salt), expansionIters, sha512.Size, sha512.New)
+			if derived := pbkdf2SHA512(password, []byte(salt), expansionIters); !bytes.Equal(derived, expected) {
+				t.Fatalf("pbkdf2SHA512(%q, %q): expected %x, got %x", password, salt, expected, derived)
+			}
+		}
+	}
+}
+
+func TestEntropyRoundTrip(t *testing.T) {
+	for _, l := range []int{16, 20, 24, 28, 32} {
+		for i := 0; i < 100; i++ {
+			entropy := make([]byte, l)
+			if _, err := rand.Read(entropy); err != nil {
+				t.Fatalf("rand.Read: %v", err)
+			}
+			mnemonic, err := EntropyToMnemonic(entropy)
+			if err != nil {
+				t.Fatalf("EntropyToMnemonic: %v", err)
+			}
+			decoded, err := MnemonicToEntropy(mnemonic)
+			if err != nil {
+				t.Fatalf("MnemonicToEntropy(%s): %v", mnemonic, err)
+			}
+			if !bytes.Equal(decoded, entropy) {
+				t.Fatalf("MnemonicToEntropy(%s): expected %x, got %x", mnemonic, entropy, decoded)
+			}
+		}
+	}
+}
+
+const benchMnemonic = "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic bless"
+
+func BenchmarkValidateAndExpandMnemonic(b *testing.B) {
+	raw := []byte(benchMnemonic)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ValidateAndExpandMnemonic(raw); err != nil {
+			b.Fatalf("ValidateAndExpandMnemonic: %v", err)
+		}
+	}
+}
+
+func BenchmarkEntropyToMnemonic(b *testing.B) {
+	entropy := bytes.Repeat([]byte{0x80}, 32)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := EntropyToMnemonic(entropy); err != nil {
+			b.Fatalf("EntropyToMnemonic: %v", err)
+		}
+	}
+}
+
+func BenchmarkMnemonicToSeed(b *testing.B) {
+	mnemonic, passphrase := []byte(benchMnemonic), []byte(passphraseTrezor)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = MnemonicToSeed(passphrase, mnemonic)
+	}
+}
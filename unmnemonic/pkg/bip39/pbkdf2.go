@@ -0,0 +1,64 @@
+package bip39
+
+import (
+	"crypto/sha512"
+	"encoding"
+)
+
+// pbkdf2SHA512 is PBKDF2-HMAC-SHA512 (RFC 8018), specialized for the
+// single block of output used by BIP-39.  The HMAC key pads are only
+// hashed once, and the resulting hash states are restored for every
+// iteration, instead of rehashing the pads.
+func pbkdf2SHA512(password, salt []byte, iter int) []byte {
+	var ipad, opad [sha512.BlockSize]byte
+	if len(password) > sha512.BlockSize {
+		sum := sha512.Sum512(password)
+		password = sum[:]
+	}
+	copy(ipad[:], password)
+	copy(opad[:], password)
+	for i := range ipad {
+		ipad[i] ^= 0x36
+		opad[i] ^= 0x5c
+	}
+
+	inner, outer := sha512.New(), sha512.New()
+	_, _ = inner.Write(ipad[:])
+	_, _ = outer.Write(opad[:])
+	innerState, err := inner.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		panic("bip39: failed to save SHA-512 state: " + err.Error())
+	}
+	outerState, err := outer.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		panic("bip39: failed to save SHA-512 state: " + err.Error())
+	}
+	innerRestorer := inner.(encoding.BinaryUnmarshaler)
+	outerRestorer := outer.(encoding.BinaryUnmarshaler)
+
+	// U_1 = PRF(P, S || INT(1))
+	var u, t [sha512.Size]byte
+	_, _ = inner.Write(salt)
+	_, _ = inner.Write([]byte{0, 0, 0, 1})
+	inner.Sum(u[:0])
+	_, _ = outer.Write(u[:])
+	outer.Sum(u[:0])
+	t = u
+
+	// U_n = PRF(P, U_{n-1}), T = U_1 ^ U_2 ^ ... ^ U_iter
+	for n := 1; n < iter; n++ {
+		_ = innerRestorer.UnmarshalBinary(innerState)
+		_, _ = inner.Write(u[:])
+		inner.Sum(u[:0])
+
+		_ = outerRestorer.UnmarshalBinary(outerState)
+		_, _ = outer.Write(u[:])
+		outer.Sum(u[:0])
+
+		for i := range t {
+			t[i] ^= u[i]
+		}
+	}
+
+	return append([]byte{}, t[:]...)
+}
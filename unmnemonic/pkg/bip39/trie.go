@@ -12,6 +12,7 @@ const wordListLength = 2048
 var (
 	//go:embed english.txt
 	englishWordList []byte
+	englishWords    []string
 	englishWordLUT  map[string]int
 	englishTrie     *trieNode
 )
@@ -89,6 +90,11 @@ func ExpandWord(prefix string) (string, error) {
 	return englishTrie.Lookup(strings.ToLower(prefix))
 }
 
+// Wordlist returns a copy of the English wordlist, in index order.
+func Wordlist() []string {
+	return append([]string{}, englishWords...)
+}
+
 func init() {
 	englishWordLUT = make(map[string]int)
 
@@ -98,6 +104,7 @@ func init() {
 	words := bytes.Fields(englishWordList)
 	for i, word := range words {
 		englishTrie.Insert(string(word))
+		englishWords = append(englishWords, string(word))
 		englishWordLUT[string(word)] = i
 	}
 	if len(words) != wordListLength {
@@ -0,0 +1,145 @@
+package bip39
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// maxPatternPeriod is the longest repeating entropy byte pattern that is
+// considered weak.
+const maxPatternPeriod = 4
+
+var (
+	//go:embed weak_mnemonics.txt
+	rawWeakMnemonics []byte
+	weakMnemonics    map[string]bool
+
+	weakSeeds     map[string]bool
+	weakSeedsOnce sync.Once
+)
+
+// WeakMnemonicError is the error returned when a mnemonic is valid, but
+// should not be used to hold real funds.
+type WeakMnemonicError struct {
+	// Reasons is the list of reasons why the mnemonic is weak.
+	Reasons []string
+}
+
+func (e *WeakMnemonicError) Error() string {
+	return "bip39: weak mnemonic: " + strings.Join(e.Reasons, ", ")
+}
+
+// CheckMnemonicStrength validates a mnemonic like ValidateAndExpandMnemonic,
+// and additionally returns a *WeakMnemonicError if the mnemonic is a
+// well-known one (eg: a published test vector or a development/demo
+// mnemonic), has very low word diversity, or encodes entropy with an
+// obvious pattern.
+func CheckMnemonicStrength(raw []byte) error {
+	expanded, entropy, err := decodeMnemonic(raw)
+	if err != nil {
+		return err
+	}
+
+	var reasons []string
+	if weakMnemonics[string(expanded)] {
+		reasons = append(reasons, "well-known test/demo mnemonic")
+	}
+
+	words := bytes.Split(expanded, []byte(" "))
+	uniqueWords := make(map[string]bool)
+	for _, word := range words {
+		uniqueWords[string(word)] = true
+	}
+	if len(uniqueWords) <= len(words)/2 {
+		reasons = append(reasons, fmt.Sprintf("low word diversity (%d unique of %d)", len(uniqueWords), len(words)))
+	}
+
+	if reason := checkEntropyPattern(entropy); reason != "" {
+		reasons = append(reasons, reason)
+	}
+
+	if len(reasons) > 0 {
+		return &WeakMnemonicError{
+			Reasons: reasons,
+		}
+	}
+	return nil
+}
+
+// CheckSeedStrength returns a *WeakMnemonicError if the BIP-39 seed is
+// derived from a well-known mnemonic (without a passphrase), or has an
+// obvious pattern.
+//
+// Note: The first call derives the seed of each well-known mnemonic, and
+// is therefore slow.
+func CheckSeedStrength(seed []byte) error {
+	if len(seed) < 2 {
+		return fmt.Errorf("bip39: invalid seed length")
+	}
+
+	weakSeedsOnce.Do(func() {
+		weakSeeds = make(map[string]bool)
+		for mnemonic := range weakMnemonics {
+			weakSeeds[string(MnemonicToSeed(nil, []byte(mnemonic)))] = true
+		}
+	})
+
+	var reasons []string
+	if weakSeeds[string(seed)] {
+		reasons = append(reasons, "seed of a well-known test/demo mnemonic")
+	}
+	if reason := checkEntropyPattern(seed); reason != "" {
+		reasons = append(reasons, strings.Replace(reason, "entropy", "seed", 1))
+	}
+
+	if len(reasons) > 0 {
+		return &WeakMnemonicError{
+			Reasons: reasons,
+		}
+	}
+	return nil
+}
+
+func checkEntropyPattern(entropy []byte) string {
+	// All bytes being identical (all-zero, all-one etc) is a special
+	// case of both checks, so check for it first for a better message.
+	isSequential := true
+	delta := entropy[1] - entropy[0]
+	for i := 2; i < len(entropy); i++ {
+		if entropy[i]-entropy[i-1] != delta {
+			isSequential = false
+			break
+		}
+	}
+	switch {
+	case isSequential && delta == 0:
+		return fmt.Sprintf("entropy is a single repeated byte (0x%02x)", entropy[0])
+	case isSequential:
+		return "entropy is a sequence"
+	}
+
+	for period := 2; period <= maxPatternPeriod; period++ {
+		if bytes.Equal(entropy[period:], entropy[:len(entropy)-period]) {
+			return fmt.Sprintf("entropy is a repeating %d byte pattern", period)
+		}
+	}
+
+	return ""
+}
+
+func init() {
+	weakMnemonics = make(map[string]bool)
+
+	scanner := bufio.NewScanner(bytes.NewReader(rawWeakMnemonics))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		weakMnemonics[line] = true
+	}
+}
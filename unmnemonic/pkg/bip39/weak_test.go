@@ -0,0 +1,75 @@
+package bip39
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckMnemonicStrength(t *testing.T) {
+	// Every embedded well-known mnemonic must be valid, and flagged.
+	if len(weakMnemonics) == 0 {
+		t.Fatalf("no well-known mnemonics embedded")
+	}
+	for mnemonic := range weakMnemonics {
+		assertWeak(t, mnemonic)
+	}
+
+	for _, mnemonic := range []string{
+		// 0x000102...0f (sequence)
+		"abandon amount liar amount expire adjust cage candy arch gather drum buyer",
+		// 0x42 * 32 (repeated byte, low word diversity)
+		"drastic bamboo mountain loyal category cancel animal embark drastic bamboo mountain loyal category cancel animal embark drastic bamboo mountain loyal category cancel animal embark",
+		// 0xdeadbeef * 4 (repeating pattern)
+		"team hospital room run swim jewel kingdom result used voice hurry that",
+	} {
+		assertWeak(t, mnemonic)
+	}
+
+	if err := CheckMnemonicStrength([]byte("owner thought surround finger stay elegant chronic grain diary indicate surround wave")); err != nil {
+		t.Fatalf("CheckMnemonicStrength: unexpected error: %v", err)
+	}
+	if err := CheckMnemonicStrength([]byte("abandon abandon abandon")); err == nil {
+		t.Fatalf("CheckMnemonicStrength: failed to reject invalid mnemonic")
+	}
+}
+
+func assertWeak(t *testing.T, mnemonic string) {
+	err := CheckMnemonicStrength([]byte(mnemonic))
+	var weakErr *WeakMnemonicError
+	if !errors.As(err, &weakErr) {
+		t.Errorf("CheckMnemonicStrength(%s): expected weak mnemonic, got %v", mnemonic, err)
+		return
+	}
+	t.Logf("%s: %v", mnemonic, weakErr)
+}
+
+func TestCheckSeedStrength(t *testing.T) {
+	for mnemonic := range weakMnemonics {
+		assertWeakSeed(t, MnemonicToSeed(nil, []byte(mnemonic)))
+	}
+
+	patterned := make([]byte, 64)
+	assertWeakSeed(t, patterned)
+	for i := range patterned {
+		patterned[i] = byte(i)
+	}
+	assertWeakSeed(t, patterned)
+
+	seed := MnemonicToSeed(nil, []byte("owner thought surround finger stay elegant chronic grain diary indicate surround wave"))
+	if err := CheckSeedStrength(seed); err != nil {
+		t.Fatalf("CheckSeedStrength: unexpected error: %v", err)
+	}
+	// A passphrase changes the seed of a well-known mnemonic.
+	seed = MnemonicToSeed([]byte("passphrase"), []byte("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"))
+	if err := CheckSeedStrength(seed); err != nil {
+		t.Fatalf("CheckSeedStrength: unexpected error: %v", err)
+	}
+}
+
+func assertWeakSeed(t *testing.T, seed []byte) {
+	err := CheckSeedStrength(seed)
+	var weakErr *WeakMnemonicError
+	if !errors.As(err, &weakErr) {
+		t.Errorf("CheckSeedStrength(%x): expected weak seed, got %v", seed, err)
+	}
+}
@@ -0,0 +1,54 @@
+package wallet_test
+
+import (
+	"fmt"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/wallet"
+)
+
+func Example() {
+	mnemonic, err := bip39.ValidateAndExpandMnemonic([]byte(
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+	))
+	if err != nil {
+		panic(err)
+	}
+	seed := bip39.MnemonicToSeed(nil, mnemonic)
+
+	d, err := wallet.New(wallet.SchemeADR0008)
+	if err != nil {
+		panic(err)
+	}
+	key, err := d.Derive(seed, d.AccountPath(0))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(key.Path, key.Address)
+	// Output: m/44'/474'/0' oasis1qqx0wgxjwlw3jwatuwqj6582hdm9rjs4pcnvzz66
+}
+
+func ExampleDeriveAccounts() {
+	mnemonic, err := bip39.ValidateAndExpandMnemonic([]byte(
+		"cross enable vendor service pulse account ceiling omit trial myself front misery",
+	))
+	if err != nil {
+		panic(err)
+	}
+	seed := bip39.MnemonicToSeed(nil, mnemonic)
+
+	d, err := wallet.New(wallet.SchemeBitpie)
+	if err != nil {
+		panic(err)
+	}
+	keys, err := wallet.DeriveAccounts(d, seed, []uint32{0})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, key := range keys {
+		fmt.Println(key.Path, key.Address)
+	}
+	// Output: m/0/0 oasis1qp8d9kuduq0zutuatjsgltpugxvl38cuaq3gzkmn
+}
@@ -0,0 +1,382 @@
+// Package wallet implements a stable interface to the various wallet key
+// derivation schemes that are or have been used by Oasis.
+//
+// See API.md in the parent directory for the compatibility guarantees
+// provided by this package.
+package wallet
+
+import (
+	"crypto"
+	"fmt"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/address"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/slip10"
+)
+
+// APIVersion is the version of the public API provided by the packages
+// under pkg/.  It is incremented on any backward incompatible change,
+// including any change to the keys derived by an existing scheme.
+const APIVersion = 1
+
+// Scheme is a wallet key derivation scheme.
+type Scheme string
+
+const (
+	// SchemeADR0008 is the ADR-0008 SLIP-0010 scheme.
+	SchemeADR0008 Scheme = "ADR-0008"
+	// SchemeLedger is the legacy pre-SLIP Oasis Ledger scheme.
+	SchemeLedger Scheme = "Ledger"
+	// SchemeBitpie is the Bitpie scheme.
+	SchemeBitpie Scheme = "Bitpie"
+	// SchemeBIP32Ed25519 is the generic BIP32-Ed25519 (Khovratovich-Law)
+	// scheme, used by some third-party wallets.
+	SchemeBIP32Ed25519 Scheme = "BIP32-Ed25519"
+)
+
+// Schemes is the list of all supported schemes.
+var Schemes = []Scheme{
+	SchemeADR0008,
+	SchemeLedger,
+	SchemeBitpie,
+	SchemeBIP32Ed25519,
+}
+
+// Key is a derived wallet key.
+type Key struct {
+	// Path is the derivation path of the key.
+	Path string
+	// PrivateKey is the private key, which is either an ed25519.PrivateKey
+	// or, for SchemeBIP32Ed25519, a bip32.ExtendedPrivateKey.
+	PrivateKey crypto.Signer
+	// PublicKey is the Ed25519 public key.
+	PublicKey ed25519.PublicKey
+	// Address is the bech32 encoded staking account address.
+	Address string
+}
+
+// Deriver is a wallet key deriver.
+type Deriver interface {
+	// Scheme returns the derivation scheme.
+	Scheme() Scheme
+
+	// AccountPath returns the derivation path for the wallet with the
+	// provided index, as used by the wallets implementing the scheme.
+	AccountPath(index uint32) string
+
+	// Derive derives the key at the provided path from a BIP-39 seed.
+	Derive(seed []byte, path string) (*Key, error)
+}
+
+// Tracer receives the intermediate derivation state, for debugging
+// mismatches against other implementations.  See bip32.Tracer, including
+// for why the trace is SENSITIVE.
+type Tracer = bip32.Tracer
+
+// Option is a Deriver option.
+type Option func(*options)
+
+type options struct {
+	tracer Tracer
+}
+
+// WithTracer traces each derivation path step to t.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+func (o *options) rootOptions() []bip32.Option {
+	if o.tracer == nil {
+		return nil
+	}
+	return []bip32.Option{bip32.WithTracer(o.tracer)}
+}
+
+// New returns a Deriver for the provided scheme.
+func New(scheme Scheme, opts ...Option) (Deriver, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	switch scheme {
+	case SchemeADR0008:
+		return &adr0008Deriver{o}, nil
+	case SchemeLedger:
+		return &ledgerDeriver{o}, nil
+	case SchemeBitpie:
+		return &bitpieDeriver{o}, nil
+	case SchemeBIP32Ed25519:
+		return &bip32Ed25519Deriver{o}, nil
+	default:
+		return nil, fmt.Errorf("wallet: unknown scheme: '%s'", scheme)
+	}
+}
+
+// childDeriver is implemented by the derivers that can derive the keys
+// for multiple wallets from their common parent node, which is only
+// derived once.
+type childDeriver interface {
+	deriveChildren(seed []byte, indexes []uint32) ([]*Key, error)
+}
+
+// DeriveAccounts derives the keys for the wallets with the provided
+// indexes, in order.  The root and the path shared by the wallets are
+// only derived once.
+func DeriveAccounts(d Deriver, seed []byte, indexes []uint32) ([]*Key, error) {
+	if cd, ok := d.(childDeriver); ok {
+		return cd.deriveChildren(seed, indexes)
+	}
+
+	keys := make([]*Key, 0, len(indexes))
+	for _, index := range indexes {
+		key, err := d.Derive(seed, d.AccountPath(index))
+		if err != nil {
+			return nil, fmt.Errorf("wallet: failed to derive key for index %d: %w", index, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+type adr0008Deriver struct {
+	options
+}
+
+func (d *adr0008Deriver) Scheme() Scheme {
+	return SchemeADR0008
+}
+
+func (d *adr0008Deriver) AccountPath(index uint32) string {
+	return fmt.Sprintf("m/44'/474'/%d'", index)
+}
+
+func (d *adr0008Deriver) Derive(seed []byte, path string) (*Key, error) {
+	indexes, err := bip32.ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, chainCode, err := slip10.NewMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive SLIP-10 master key: %w", err)
+	}
+	for _, index := range indexes {
+		if secret, chainCode, err = d.deriveChild(secret, chainCode, index); err != nil {
+			return nil, err
+		}
+	}
+
+	return newKey(path, ed25519.NewKeyFromSeed(secret[:]))
+}
+
+func (d *adr0008Deriver) deriveChildren(seed []byte, indexes []uint32) ([]*Key, error) {
+	secret, chainCode, err := slip10.NewMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive SLIP-10 master key: %w", err)
+	}
+	for _, index := range []uint32{44, 474} {
+		if secret, chainCode, err = d.deriveChild(secret, chainCode, index+bip32.HardenedIndexOffset); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]*Key, 0, len(indexes))
+	for _, index := range indexes {
+		if err = checkAccountIndex(index); err != nil {
+			return nil, err
+		}
+		childSecret, _, err := d.deriveChild(secret, chainCode, index+bip32.HardenedIndexOffset)
+		if err != nil {
+			return nil, fmt.Errorf("wallet: failed to derive key for index %d: %w", index, err)
+		}
+		key, err := newKey(d.AccountPath(index), ed25519.NewKeyFromSeed(childSecret[:]))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (d *adr0008Deriver) deriveChild(secret *slip10.Secret, chainCode *slip10.ChainCode, index uint32) (*slip10.Secret, *slip10.ChainCode, error) {
+	secret, chainCode, err := slip10.NewChildKey(secret, chainCode, index)
+	if err != nil {
+		return nil, nil, fmt.Errorf("wallet: failed to derive SLIP-10 child key: %w", err)
+	}
+	if d.tracer != nil {
+		pk := ed25519.NewKeyFromSeed(secret[:]).Public().(ed25519.PublicKey)
+		d.tracer.Step("slip10", index, chainCode[:], pk)
+	}
+	return secret, chainCode, nil
+}
+
+type ledgerDeriver struct {
+	options
+}
+
+func (d *ledgerDeriver) Scheme() Scheme {
+	return SchemeLedger
+}
+
+func (d *ledgerDeriver) AccountPath(index uint32) string {
+	return fmt.Sprintf("m/44'/474'/0'/0'/%d'", index)
+}
+
+func (d *ledgerDeriver) Derive(seed []byte, path string) (*Key, error) {
+	root, err := bip32.NewLedgerRoot(seed, d.rootOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive BIP32-Ed25519 root: %w", err)
+	}
+	child, err := root.DerivePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKey(path, child.GetLedgerPrivateKey())
+}
+
+func (d *ledgerDeriver) deriveChildren(seed []byte, indexes []uint32) ([]*Key, error) {
+	root, err := bip32.NewLedgerRoot(seed, d.rootOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive BIP32-Ed25519 root: %w", err)
+	}
+	return deriveNodeChildren(d, root, "m/44'/474'/0'/0'", bip32.HardenedIndexOffset, indexes, func(n *bip32.Node) crypto.Signer {
+		return n.GetLedgerPrivateKey()
+	})
+}
+
+type bitpieDeriver struct {
+	options
+}
+
+func (d *bitpieDeriver) Scheme() Scheme {
+	return SchemeBitpie
+}
+
+func (d *bitpieDeriver) AccountPath(index uint32) string {
+	// The Bitpie root is already at (secp256k1) m/44'/474'/0'.
+	return fmt.Sprintf("m/0/%d", index)
+}
+
+func (d *bitpieDeriver) Derive(seed []byte, path string) (*Key, error) {
+	root, err := bip32.NewBitpieRoot(seed, d.rootOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive Bitpie root: %w", err)
+	}
+	child, err := root.DerivePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKey(path, child.GetBitpiePrivateKey())
+}
+
+func (d *bitpieDeriver) deriveChildren(seed []byte, indexes []uint32) ([]*Key, error) {
+	root, err := bip32.NewBitpieRoot(seed, d.rootOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive Bitpie root: %w", err)
+	}
+	return deriveNodeChildren(d, root, "m/0", 0, indexes, func(n *bip32.Node) crypto.Signer {
+		return n.GetBitpiePrivateKey()
+	})
+}
+
+type bip32Ed25519Deriver struct {
+	options
+}
+
+func (d *bip32Ed25519Deriver) Scheme() Scheme {
+	return SchemeBIP32Ed25519
+}
+
+func (d *bip32Ed25519Deriver) AccountPath(index uint32) string {
+	return fmt.Sprintf("m/44'/474'/0'/0'/%d'", index)
+}
+
+func (d *bip32Ed25519Deriver) Derive(seed []byte, path string) (*Key, error) {
+	root, err := bip32.NewRoot(seed, d.rootOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive BIP32-Ed25519 root: %w", err)
+	}
+	child, err := root.DerivePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unlike the Ledger variant, kL || kR is used as-is as the
+	// expanded Ed25519 secret, so there is no RFC 8032 seed.
+	return newKey(path, child.GetExtendedPrivateKey())
+}
+
+func (d *bip32Ed25519Deriver) deriveChildren(seed []byte, indexes []uint32) ([]*Key, error) {
+	root, err := bip32.NewRoot(seed, d.rootOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive BIP32-Ed25519 root: %w", err)
+	}
+	return deriveNodeChildren(d, root, "m/44'/474'/0'/0'", bip32.HardenedIndexOffset, indexes, func(n *bip32.Node) crypto.Signer {
+		return n.GetExtendedPrivateKey()
+	})
+}
+
+// deriveNodeChildren derives the parent at parentPath from the root once,
+// and then the key for each wallet as the parent's child at the wallet
+// index plus offset.
+func deriveNodeChildren(
+	d Deriver,
+	root *bip32.Node,
+	parentPath string,
+	offset uint32,
+	indexes []uint32,
+	privateKeyFn func(*bip32.Node) crypto.Signer,
+) ([]*Key, error) {
+	parent, err := root.DerivePath(parentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*Key, 0, len(indexes))
+	for _, index := range indexes {
+		if err = checkAccountIndex(index); err != nil {
+			return nil, err
+		}
+		child, err := parent.DeriveChild(index + offset)
+		if err != nil {
+			return nil, fmt.Errorf("wallet: failed to derive key for index %d: %w", index, err)
+		}
+		key, err := newKey(d.AccountPath(index), privateKeyFn(child))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// checkAccountIndex returns an error iff the wallet index can't be used
+// in a path (see bip32.ParsePath).
+func checkAccountIndex(index uint32) error {
+	if index >= bip32.HardenedIndexOffset {
+		return fmt.Errorf("wallet: index %d out of range", index)
+	}
+	return nil
+}
+
+func newKey(path string, privateKey crypto.Signer) (*Key, error) {
+	publicKey := privateKey.Public().(ed25519.PublicKey)
+	addr, err := address.FromPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("wallet: failed to derive address: %w", err)
+	}
+
+	return &Key{
+		Path:       path,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+		Address:    addr,
+	}, nil
+}
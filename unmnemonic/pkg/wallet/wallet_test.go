@@ -0,0 +1,253 @@
+package wallet
+
+import (
+	"crypto"
+	"encoding/hex"
+	"testing"
+
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+)
+
+func TestDeriver(t *testing.T) {
+	for _, v := range []struct {
+		scheme   Scheme
+		mnemonic string
+		path     string
+
+		expectedPublicKey string
+		expectedAddress   string
+	}{
+		{
+			// From the reference implementation of the Ledger app derivation.
+			scheme:            SchemeLedger,
+			mnemonic:          "equip will roof matter pink blind book anxiety banner elbow sun young",
+			path:              "m/44'/474'/5'/0'/3'",
+			expectedPublicKey: "aba52c0dcb80c2fe96ed4c3741af40c573a0500c0d73acda22795c37cb0f1739",
+		},
+		{
+			// From the test vector provided by Bitpie, and a wallet export.
+			scheme:            SchemeBitpie,
+			mnemonic:          "cross enable vendor service pulse account ceiling omit trial myself front misery",
+			path:              "m/0/0",
+			expectedPublicKey: "afa004d2863641f69a6ea725cb7abca70d6069c476ec3ed119c6dc6c72fa4e79",
+			expectedAddress:   "oasis1qp8d9kuduq0zutuatjsgltpugxvl38cuaq3gzkmn",
+		},
+		{
+			// From the ADR-0008 test vectors.
+			scheme:          SchemeADR0008,
+			mnemonic:        "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			path:            "m/44'/474'/0'",
+			expectedAddress: "oasis1qqx0wgxjwlw3jwatuwqj6582hdm9rjs4pcnvzz66",
+		},
+	} {
+		t.Run(string(v.scheme), func(t *testing.T) {
+			d, err := New(v.scheme)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if d.Scheme() != v.scheme {
+				t.Fatalf("Scheme: expected %s, got %s", v.scheme, d.Scheme())
+			}
+
+			key, err := d.Derive(mustSeed(t, v.mnemonic), v.path)
+			if err != nil {
+				t.Fatalf("Derive(%s): %v", v.path, err)
+			}
+			if v.expectedPublicKey != "" {
+				if pkStr := hex.EncodeToString(key.PublicKey); pkStr != v.expectedPublicKey {
+					t.Fatalf("public key mismatch, expected %s, got %s", v.expectedPublicKey, pkStr)
+				}
+			}
+			if v.expectedAddress != "" && key.Address != v.expectedAddress {
+				t.Fatalf("address mismatch, expected %s, got %s", v.expectedAddress, key.Address)
+			}
+		})
+	}
+}
+
+func TestDeriveAccounts(t *testing.T) {
+	seed := mustSeed(t, "legal winner thank year wave sausage worth useful legal winner thank yellow")
+	msg := []byte("wallet test message")
+
+	for _, scheme := range Schemes {
+		t.Run(string(scheme), func(t *testing.T) {
+			d, err := New(scheme)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			keys, err := DeriveAccounts(d, seed, []uint32{0, 1, 5})
+			if err != nil {
+				t.Fatalf("DeriveAccounts: %v", err)
+			}
+			if len(keys) != 3 {
+				t.Fatalf("DeriveAccounts: unexpected number of keys: %d", len(keys))
+			}
+			seen := make(map[string]bool)
+			for _, key := range keys {
+				if seen[key.Address] {
+					t.Fatalf("duplicate address: %s", key.Address)
+				}
+				seen[key.Address] = true
+
+				sig, err := key.PrivateKey.Sign(nil, msg, crypto.Hash(0))
+				if err != nil {
+					t.Fatalf("Sign: %v", err)
+				}
+				if !ed25519.Verify(key.PublicKey, msg, sig) {
+					t.Fatalf("signature failed to verify (%s)", key.Path)
+				}
+			}
+		})
+	}
+
+	if _, err := New("Bogus"); err == nil {
+		t.Fatalf("New: failed to reject unknown scheme")
+	}
+}
+
+func TestDeriveAccountsMatchesDerive(t *testing.T) {
+	seed := mustSeed(t, "legal winner thank year wave sausage worth useful legal winner thank yellow")
+	indexes := []uint32{0, 1, 2, 7, 1000}
+
+	for _, scheme := range Schemes {
+		t.Run(string(scheme), func(t *testing.T) {
+			d, err := New(scheme)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			keys, err := DeriveAccounts(d, seed, indexes)
+			if err != nil {
+				t.Fatalf("DeriveAccounts: %v", err)
+			}
+			for i, index := range indexes {
+				expected, err := d.Derive(seed, d.AccountPath(index))
+				if err != nil {
+					t.Fatalf("Derive(%d): %v", index, err)
+				}
+				if keys[i].Path != expected.Path || keys[i].Address != expected.Address {
+					t.Fatalf("index %d: expected %s (%s), got %s (%s)", index, expected.Address, expected.Path, keys[i].Address, keys[i].Path)
+				}
+			}
+
+			if _, err = DeriveAccounts(d, seed, []uint32{bip32.HardenedIndexOffset}); err == nil {
+				t.Fatalf("DeriveAccounts: failed to reject out of range index")
+			}
+		})
+	}
+}
+
+type testTracer struct {
+	steps  []string
+	printf int
+}
+
+func (t *testTracer) Step(scheme string, index uint32, chainCode []byte, publicKey ed25519.PublicKey) {
+	t.steps = append(t.steps, scheme)
+}
+
+func (t *testTracer) Printf(format string, a ...interface{}) {
+	t.printf++
+}
+
+func TestTracer(t *testing.T) {
+	seed := mustSeed(t, "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	for _, v := range []struct {
+		scheme         Scheme
+		expectedScheme string
+		expectedPrintf int
+	}{
+		{SchemeADR0008, "slip10", 0},
+		{SchemeLedger, "bip32-ed25519", 1},
+		{SchemeBitpie, "bitpie", 0},
+	} {
+		t.Run(string(v.scheme), func(t *testing.T) {
+			var tr testTracer
+			d, err := New(v.scheme, WithTracer(&tr))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			path := d.AccountPath(0)
+			indexes, _ := bip32.ParsePath(path)
+			if _, err = d.Derive(seed, path); err != nil {
+				t.Fatalf("Derive(%s): %v", path, err)
+			}
+			if len(tr.steps) != len(indexes) {
+				t.Fatalf("expected %d traced steps, got %d", len(indexes), len(tr.steps))
+			}
+			for _, scheme := range tr.steps {
+				if scheme != v.expectedScheme {
+					t.Fatalf("expected %s steps, got %s", v.expectedScheme, scheme)
+				}
+			}
+			if tr.printf != v.expectedPrintf {
+				t.Fatalf("expected %d traced messages, got %d", v.expectedPrintf, tr.printf)
+			}
+
+			// Derivers without a tracer are not affected.
+			untraced, _ := New(v.scheme)
+			if _, err = untraced.Derive(seed, path); err != nil {
+				t.Fatalf("Derive(%s): %v", path, err)
+			}
+			if len(tr.steps) != len(indexes) {
+				t.Fatalf("untraced deriver wrote to the tracer")
+			}
+		})
+	}
+}
+
+func mustSeed(t *testing.T, mnemonic string) []byte {
+	m, err := bip39.ValidateAndExpandMnemonic([]byte(mnemonic))
+	if err != nil {
+		t.Fatalf("ValidateAndExpandMnemonic: %v", err)
+	}
+	return bip39.MnemonicToSeed(nil, m)
+}
+
+func BenchmarkDerive(b *testing.B) {
+	seed := bip39.MnemonicToSeed(nil, []byte("equip will roof matter pink blind book anxiety banner elbow sun young"))
+	for _, scheme := range Schemes {
+		b.Run(string(scheme), func(b *testing.B) {
+			d, err := New(scheme)
+			if err != nil {
+				b.Fatalf("New: %v", err)
+			}
+			path := d.AccountPath(0)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err = d.Derive(seed, path); err != nil {
+					b.Fatalf("Derive: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkDeriveAccounts(b *testing.B) {
+	seed := bip39.MnemonicToSeed(nil, []byte("equip will roof matter pink blind book anxiety banner elbow sun young"))
+	indexes := make([]uint32, 20)
+	for i := range indexes {
+		indexes[i] = uint32(i)
+	}
+	for _, scheme := range Schemes {
+		b.Run(string(scheme), func(b *testing.B) {
+			d, err := New(scheme)
+			if err != nil {
+				b.Fatalf("New: %v", err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err = DeriveAccounts(d, seed, indexes); err != nil {
+					b.Fatalf("DeriveAccounts: %v", err)
+				}
+			}
+		})
+	}
+}
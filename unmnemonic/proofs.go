@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+
+	"github.com/oasisprotocol/tools/unmnemonic/internal/proof"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip32"
+)
+
+// doProofOfControl signs a proof of control statement with each of the
+// exported keys, as read back from disk, so that the user knows that the
+// exported keys can actually produce valid signatures.
+func doProofOfControl(dir string, infos []*walletInfo) error {
+	var ok bool
+	if err := survey.AskOne(&survey.Confirm{
+		Message: "Sign a proof of control statement with each exported key",
+		Default: true,
+	}, &ok); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	fmt.Printf(" The chain context (eg: from `oasis-node control status`) binds the\n")
+	fmt.Printf(" statements to a specific network, and can be left empty.\n")
+	var chainContext string
+	if err := survey.AskOne(&survey.Input{
+		Message: "Chain context",
+	}, &chainContext); err != nil {
+		return err
+	}
+	chainContext = strings.TrimSpace(chainContext)
+
+	now := time.Now()
+	for _, info := range infos {
+		fn := info.fileName()
+		b, err := os.ReadFile(filepath.Join(dir, fn))
+		if err != nil {
+			return fmt.Errorf("failed to read back private key: %w", err)
+		}
+		signer, err := decodePrivateFromPEMBuf(b)
+		if err != nil {
+			return fmt.Errorf("failed to decode private key '%s': %w", fn, err)
+		}
+
+		s, err := proof.NewStatement(signer, chainContext, now)
+		if err != nil {
+			return fmt.Errorf("signing smoke test FAILED for index %d: %w", info.index, err)
+		}
+		if s.Address != info.address {
+			return fmt.Errorf("signing smoke test FAILED for index %d: key file is for '%s'", info.index, s.Address)
+		}
+
+		if b, err = s.MarshalIndent(); err != nil {
+			return err
+		}
+		proofFn := strings.TrimSuffix(fn, ".private.pem") + ".proof.json"
+		if err = os.WriteFile(filepath.Join(dir, proofFn), b, 0o644); err != nil {
+			return fmt.Errorf("failed to write proof of control statement: %w", err)
+		}
+		fmt.Printf(" Index[%d]: %s - signature ok\n", info.index, proofFn)
+	}
+
+	return nil
+}
+
+func decodePrivateFromPEMBuf(b []byte) (crypto.Signer, error) {
+	blk, rest := pem.Decode(b)
+	if blk == nil || len(bytes.TrimSpace(rest)) != 0 {
+		return nil, fmt.Errorf("malformed PEM")
+	}
+
+	switch blk.Type {
+	case "ED25519 PRIVATE KEY":
+		if len(blk.Bytes) != ed25519.PrivateKeySize {
+			return nil, fmt.Errorf("malformed private key")
+		}
+		return ed25519.PrivateKey(blk.Bytes), nil
+	case "ED25519 EXTENDED PRIVATE KEY":
+		if len(blk.Bytes) != bip32.ExtendedPrivateKeySize+ed25519.PublicKeySize {
+			return nil, fmt.Errorf("malformed extended private key")
+		}
+		k := bip32.ExtendedPrivateKey(blk.Bytes[:bip32.ExtendedPrivateKeySize])
+		pk, err := k.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("malformed extended private key: %w", err)
+		}
+		if !bytes.Equal(pk, blk.Bytes[bip32.ExtendedPrivateKeySize:]) {
+			return nil, fmt.Errorf("extended private key does not match the public key")
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported PEM type: '%s'", blk.Type)
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/oasisprotocol/tools/unmnemonic/internal/recovery"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/address"
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/wallet"
+)
+
+var shard string
+
+const (
+	candidatesWordlist  = "Wordlist file"
+	candidatesMask      = "Mask (eg: Summer20??!)"
+	candidatesMutations = "Mutations of guesses"
+
+	mutateCase = "Letter case"
+	mutateLeet = "Leet speak (eg: a -> 4, @)"
+
+	defaultCheckpointFile = "unmnemonic-recovery.checkpoint"
+)
+
+func doRecoverPassphrase() error {
+	if err := doRecoveryPreflight(); err != nil {
+		return err
+	}
+
+	fmt.Printf(" This will search for a forgotten BIP-39 passphrase, by trying each\n")
+	fmt.Printf(" candidate against the address of one of the wallet's accounts.\n")
+	fmt.Printf("\n")
+
+	d, err := askDeriver()
+	if err != nil {
+		return err
+	}
+	mnemonic, err := askMnemonic()
+	if err != nil {
+		return err
+	}
+	target, indexes, err := askRecoveryTarget()
+	if err != nil {
+		return err
+	}
+	space, err := askCandidateSpace()
+	if err != nil {
+		return err
+	}
+
+	check, err := recovery.NewPassphraseCheck(d, mnemonic, target, indexes)
+	if err != nil {
+		return err
+	}
+	// The passphrase guesses are low entropy, so the checkpoint is keyed
+	// with the mnemonic, which is never written to disk.
+	result, err := runSearch(space, check, "passphrase:"+string(d.Scheme())+":"+target, mnemonic)
+	if err != nil || result == nil {
+		return err
+	}
+
+	fmt.Printf(" Passphrase found: '%s'\n", result.Candidate)
+	fmt.Printf(" To export the keys, run unmnemonic and enter the passphrase at the\n")
+	fmt.Printf(" BIP-39 passphrase prompt.\n")
+
+	return nil
+}
+
+func doRecoverMissingWords() error {
+	if err := doRecoveryPreflight(); err != nil {
+		return err
+	}
+
+	fmt.Printf(" This will search for missing mnemonic words, by trying each word\n")
+	fmt.Printf(" against the address of one of the wallet's accounts.  Enter `?` for\n")
+	fmt.Printf(" each missing word.\n")
+	fmt.Printf("\n")
+
+	d, err := askDeriver()
+	if err != nil {
+		return err
+	}
+	words, err := askPartialMnemonic(true)
+	if err != nil {
+		return err
+	}
+	space, err := recovery.NewMissingWordSpace(words)
+	if err != nil {
+		return err
+	}
+
+	return doRecoverMnemonic(d, space, "missing-words")
+}
+
+func doRecoverWordOrder() error {
+	if err := doRecoveryPreflight(); err != nil {
+		return err
+	}
+
+	fmt.Printf(" This will search for the correct order of the mnemonic words, by\n")
+	fmt.Printf(" trying each order against the address of one of the wallet's\n")
+	fmt.Printf(" accounts.\n")
+	fmt.Printf("\n")
+
+	d, err := askDeriver()
+	if err != nil {
+		return err
+	}
+	words, err := askPartialMnemonic(false)
+	if err != nil {
+		return err
+	}
+
+	var s string
+	if err = survey.AskOne(&survey.Input{
+		Message: "Word positions that may be out of order (eg: 1-6,9, empty for all)",
+	}, &s, survey.WithValidator(isOptionalIndexList)); err != nil {
+		return err
+	}
+	var positions []int
+	if strings.TrimSpace(s) != "" {
+		specs, _ := parseIndexes(s)
+		for _, spec := range specs {
+			positions = append(positions, int(spec.index)-1)
+		}
+	}
+	space, err := recovery.NewWordOrderSpace(words, positions)
+	if err != nil {
+		return err
+	}
+
+	return doRecoverMnemonic(d, space, "word-order")
+}
+
+func doRecoverMnemonic(d wallet.Deriver, space recovery.Space, mode string) error {
+	passphrase, err := askPassphrase()
+	if err != nil {
+		return err
+	}
+	target, indexes, err := askRecoveryTarget()
+	if err != nil {
+		return err
+	}
+
+	check, err := recovery.NewMnemonicCheck(d, passphrase, target, indexes)
+	if err != nil {
+		return err
+	}
+	// The known words can't be brute-forced from the checkpoint, but key
+	// it with the passphrase (if any) anyway, which is never written to
+	// disk.
+	result, err := runSearch(space, check, mode+":"+string(d.Scheme())+":"+target, passphrase)
+	if err != nil || result == nil {
+		return err
+	}
+
+	fmt.Printf(" Mnemonic found: %s\n", result.Candidate)
+
+	return nil
+}
+
+func doRecoveryPreflight() error {
+	if err := doSelftest(); err != nil {
+		return err
+	}
+	return doAirgapCheck()
+}
+
+func askDeriver() (wallet.Deriver, error) {
+	var algo string
+	if err := survey.AskOne(&survey.Select{
+		Message: "Which algorithm does your wallet use",
+		Options: []string{algoAdr0008, algoLedger, algoBitpie, algoBIP32Ed25519},
+	}, &algo); err != nil {
+		return nil, err
+	}
+	return wallet.New(wallet.Scheme(algo))
+}
+
+func askPartialMnemonic(allowMissing bool) ([]string, error) {
+	var s string
+	if err := survey.AskOne(&survey.Input{
+		Message: "How many words is your mnemonic",
+		Default: "24",
+	}, &s, survey.WithValidator(isMnemonicLength)); err != nil {
+		return nil, err
+	}
+
+	validator := isMnemonicWord
+	if allowMissing {
+		validator = isMnemonicWordOrMissing
+	}
+
+	mnemonicLength, _ := strconv.ParseUint(s, 10, 32)
+	words := make([]string, 0, int(mnemonicLength))
+	for i := 1; i <= int(mnemonicLength); i++ {
+		if err := survey.AskOne(&survey.Password{
+			Message: fmt.Sprintf("Enter word %d", i),
+		}, &s, survey.WithValidator(validator)); err != nil {
+			return nil, err
+		}
+		words = append(words, strings.TrimSpace(s))
+	}
+	return words, nil
+}
+
+func askRecoveryTarget() (string, []uint32, error) {
+	var target string
+	if err := survey.AskOne(&survey.Input{
+		Message: "Known account address",
+	}, &target, survey.WithValidator(isAddress)); err != nil {
+		return "", nil, err
+	}
+
+	var s string
+	if err := survey.AskOne(&survey.Input{
+		Message: "Wallet index(es) the address could be at (eg: 0-4)",
+		Default: "0",
+	}, &s, survey.WithValidator(isIndexList)); err != nil {
+		return "", nil, err
+	}
+	specs, _ := parseIndexes(s)
+	indexes := make([]uint32, 0, len(specs))
+	for _, spec := range specs {
+		indexes = append(indexes, spec.index)
+	}
+
+	return strings.TrimSpace(target), indexes, nil
+}
+
+// runSearch runs a search, resuming from and checkpointing to a user
+// provided checkpoint file, keyed with key (see recovery.Options).
+// Interrupting the search (eg: with Ctrl-C) writes a final checkpoint.
+func runSearch(space recovery.Space, check recovery.CheckFunc, id string, key []byte) (*recovery.Result, error) {
+	shardIdx, shards, err := parseShard(shard)
+	if err != nil {
+		return nil, err
+	}
+	if shards > 1 {
+		fmt.Printf(" Candidates: %d (searching shard %s)\n", space.Len(), shard)
+	} else {
+		fmt.Printf(" Candidates: %d\n", space.Len())
+	}
+
+	var checkpointFile string
+	if err = survey.AskOne(&survey.Input{
+		Message: "Checkpoint file (resumes the search if it exists)",
+		Default: defaultCheckpointFile,
+	}, &checkpointFile); err != nil {
+		return nil, err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf(" Searching, press Ctrl-C to stop (the search can be resumed later).\n")
+	result, err := recovery.Search(ctx, space, check, &recovery.Options{
+		ID:             id,
+		Key:            key,
+		Shard:          shardIdx,
+		Shards:         shards,
+		CheckpointFile: strings.TrimSpace(checkpointFile),
+		Progress: func(done, total uint64) {
+			fmt.Printf(" Progress: %d/%d (%.2f%%)\n", done, total, 100*float64(done)/float64(total))
+		},
+	})
+	switch {
+	case errors.Is(err, context.Canceled):
+		return nil, fmt.Errorf("search interrupted, progress saved to '%s'", checkpointFile)
+	case err != nil:
+		return nil, err
+	case result == nil:
+		fmt.Printf(" No candidate matched.\n")
+		os.Exit(1)
+	}
+
+	return result, nil
+}
+
+// parseShard parses a 1-based `i/n` shard specification, and returns
+// the 0-based shard index, and the number of shards.
+func parseShard(s string) (int, int, error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	spl := strings.Split(s, "/")
+	if len(spl) != 2 {
+		return 0, 0, fmt.Errorf("invalid shard: '%s'", s)
+	}
+	i, err := strconv.Atoi(spl[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid shard: '%s'", s)
+	}
+	n, err := strconv.Atoi(spl[1])
+	if err != nil || n < 1 || i < 1 || i > n {
+		return 0, 0, fmt.Errorf("invalid shard: '%s'", s)
+	}
+	return i - 1, n, nil
+}
+
+func askCandidateSpace() (recovery.Space, error) {
+	var source string
+	if err := survey.AskOne(&survey.Select{
+		Message: "What passphrase candidates should be tried",
+		Options: []string{candidatesWordlist, candidatesMask, candidatesMutations},
+	}, &source); err != nil {
+		return nil, err
+	}
+
+	switch source {
+	case candidatesWordlist:
+		var fn string
+		if err := survey.AskOne(&survey.Input{
+			Message: "Wordlist file (one candidate per line)",
+		}, &fn, survey.WithValidator(survey.Required)); err != nil {
+			return nil, err
+		}
+		f, err := os.Open(strings.TrimSpace(fn))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open wordlist: %w", err)
+		}
+		defer f.Close()
+		return recovery.NewWordlistSpace(f)
+	case candidatesMask:
+		fmt.Printf(" Each `?` in the mask matches any one character from the charset,\n")
+		fmt.Printf(" `\\` escapes the next character.\n")
+
+		var mask, charset string
+		if err := survey.AskOne(&survey.Password{
+			Message: "Mask",
+		}, &mask, survey.WithValidator(survey.Required)); err != nil {
+			return nil, err
+		}
+		if err := survey.AskOne(&survey.Input{
+			Message: "Charset",
+			Default: recovery.DefaultMaskCharset,
+		}, &charset); err != nil {
+			return nil, err
+		}
+		return recovery.NewMaskSpace(mask, charset)
+	case candidatesMutations:
+		guesses, err := askGuesses()
+		if err != nil {
+			return nil, err
+		}
+
+		var mutations []string
+		if err := survey.AskOne(&survey.MultiSelect{
+			Message: "Mutations to try",
+			Options: []string{mutateCase, mutateLeet},
+			Default: []string{mutateCase, mutateLeet},
+		}, &mutations); err != nil {
+			return nil, err
+		}
+		var opts recovery.MutationOptions
+		for _, v := range mutations {
+			switch v {
+			case mutateCase:
+				opts.Case = true
+			case mutateLeet:
+				opts.Leet = true
+			}
+		}
+
+		var spaces []recovery.Space
+		for _, guess := range guesses {
+			space, err := recovery.NewMutationSpace(guess, opts)
+			if err != nil {
+				return nil, err
+			}
+			spaces = append(spaces, space)
+		}
+		return recovery.Concat(spaces...)
+	default:
+		return nil, fmt.Errorf("unknown candidate source")
+	}
+}
+
+// askGuesses prompts for passphrase guesses, one per prompt, until an
+// empty guess is entered.  Guesses are taken verbatim, so they may contain
+// any character.
+func askGuesses() ([]string, error) {
+	fmt.Printf(" Enter one guess per prompt, and an empty guess when done.\n")
+
+	var guesses []string
+	for {
+		var guess string
+		if err := survey.AskOne(&survey.Password{
+			Message: fmt.Sprintf("Guess %d", len(guesses)+1),
+		}, &guess); err != nil {
+			return nil, err
+		}
+		if guess == "" {
+			break
+		}
+		guesses = append(guesses, guess)
+	}
+	if len(guesses) == 0 {
+		return nil, fmt.Errorf("no guesses entered")
+	}
+	return guesses, nil
+}
+
+func isMnemonicWordOrMissing(val interface{}) error {
+	if strings.TrimSpace(val.(string)) == "?" {
+		return nil
+	}
+	return isMnemonicWord(val)
+}
+
+func isOptionalIndexList(val interface{}) error {
+	if strings.TrimSpace(val.(string)) == "" {
+		return nil
+	}
+	return isIndexList(val)
+}
+
+func isAddress(val interface{}) error {
+	_, err := address.Decode(strings.TrimSpace(val.(string)))
+	return err
+}
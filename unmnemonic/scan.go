@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/oasisprotocol/tools/unmnemonic/internal/balance"
+)
+
+const (
+	defaultScanGapLimit = 20
+	defaultScanMaxIndex = 1000
+
+	scanQueryTimeout = 30 * time.Second
+)
+
+func doScan(deriveFn deriveFunc, seed []byte) ([]*walletInfo, error) {
+	fmt.Printf(" Accounts can be looked up via a local node's gRPC socket\n")
+	fmt.Printf(" (eg: `unix:/node/data/internal.sock`), or an offline state dump\n")
+	fmt.Printf(" (eg: from `oasis-node debug dumpdb`).  Node lookups require a\n")
+	fmt.Printf(" build with `-tags grpc`.\n")
+
+	var location string
+	if err := survey.AskOne(&survey.Input{
+		Message: "Node gRPC socket or state dump file",
+	}, &location, survey.WithValidator(survey.Required)); err != nil {
+		return nil, err
+	}
+
+	var s string
+	if err := survey.AskOne(&survey.Input{
+		Message: "Stop after how many consecutive empty accounts",
+		Default: strconv.Itoa(defaultScanGapLimit),
+	}, &s, survey.WithValidator(isNonZeroUint32)); err != nil {
+		return nil, err
+	}
+	gapLimit, _ := strconv.ParseUint(s, 10, 32)
+
+	if err := survey.AskOne(&survey.Input{
+		Message: "Maximum wallet index to scan",
+		Default: strconv.Itoa(defaultScanMaxIndex),
+	}, &s, survey.WithValidator(isUint32Index)); err != nil {
+		return nil, err
+	}
+	maxIndex, _ := strconv.ParseUint(s, 10, 32)
+
+	src, err := balance.NewSource(location)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	return scanAccounts(src, deriveFn, seed, uint32(gapLimit), uint32(maxIndex))
+}
+
+// scanAccounts derives wallets starting at index 0, and returns the ones
+// that have been used, stopping after gapLimit consecutive empty accounts
+// or maxIndex, whichever comes first.
+func scanAccounts(src balance.Source, deriveFn deriveFunc, seed []byte, gapLimit, maxIndex uint32) ([]*walletInfo, error) {
+	var (
+		infos []*walletInfo
+		gap   uint32
+		next  uint64
+	)
+	for gap < gapLimit && next <= uint64(maxIndex) {
+		// Derive in batches of the remaining gap, since the common
+		// parent of the wallets is only derived once per batch (see
+		// wallet.DeriveAccounts).
+		indexes := make([]uint32, 0, gapLimit-gap)
+		for len(indexes) < cap(indexes) && next <= uint64(maxIndex) {
+			indexes = append(indexes, uint32(next))
+			next++
+		}
+		batch, err := deriveFn(seed, indexes)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, info := range batch {
+			ctx, cancel := context.WithTimeout(context.Background(), scanQueryTimeout)
+			info.account, err = src.GetAccount(ctx, info.address)
+			cancel()
+			if err != nil {
+				return nil, fmt.Errorf("failed to query account for index %d: %w", info.index, err)
+			}
+
+			if info.account.IsEmpty() {
+				gap++
+				fmt.Printf(" Index[%d]: %s - empty\n", info.index, info.address)
+				if gap >= gapLimit {
+					break
+				}
+				continue
+			}
+			gap = 0
+			infos = append(infos, info)
+		}
+	}
+
+	fmt.Printf(" Scan found %d used account(s)\n", len(infos))
+
+	return infos, nil
+}
+
+func isNonZeroUint32(val interface{}) error {
+	s := val.(string)
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || v == 0 {
+		return fmt.Errorf("invalid number: '%s'", s)
+	}
+	return nil
+}
+
+func isUint32Index(val interface{}) error {
+	s := val.(string)
+	v, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return fmt.Errorf("invalid index: '%s'", s)
+	}
+	if v > uint64(maxAccountKeyNumber) {
+		return fmt.Errorf("invalid index (out of range): '%s'", s)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AlecAivazis/survey/v2"
+
+	"github.com/oasisprotocol/tools/unmnemonic/pkg/bip39"
+)
+
+const (
+	inputMnemonic = "Mnemonic"
+	inputEntropy  = "Entropy (hex)"
+	inputSeed     = "BIP-39 seed (hex)"
+
+	seedSize = 64
+)
+
+// askSeed prompts for the wallet secret in one of the supported formats,
+// and returns the BIP-39 seed.  Mnemonics (and entropy) are combined with
+// the optional BIP-39 passphrase.
+func askSeed() ([]byte, error) {
+	var input string
+	if err := survey.AskOne(&survey.Select{
+		Message: "What form is your wallet secret in",
+		Options: []string{inputMnemonic, inputEntropy, inputSeed},
+	}, &input); err != nil {
+		return nil, err
+	}
+
+	switch input {
+	case inputSeed:
+		// The seed is used as-is, skipping PBKDF2.
+		var s string
+		if err := survey.AskOne(&survey.Password{
+			Message: "Enter the seed (hex)",
+		}, &s, survey.WithValidator(isHexSeed)); err != nil {
+			return nil, err
+		}
+		seed, err := hex.DecodeString(strings.TrimSpace(s))
+		if err != nil {
+			return nil, err
+		}
+		if err = confirmWeakSecret("seed", bip39.CheckSeedStrength(seed)); err != nil {
+			return nil, err
+		}
+		return seed, nil
+	default:
+		mnemonic, err := askMnemonicOrEntropy(input)
+		if err != nil {
+			return nil, err
+		}
+		passphrase, err := askPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return bip39.MnemonicToSeed(passphrase, mnemonic), nil
+	}
+}
+
+// askPassphrase prompts for the (optional) BIP-39 passphrase.
+func askPassphrase() ([]byte, error) {
+	var s string
+	if err := survey.AskOne(&survey.Password{
+		Message: "BIP-39 passphrase (usually empty)",
+	}, &s); err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+func askMnemonicOrEntropy(input string) ([]byte, error) {
+	var (
+		mnemonic []byte
+		err      error
+	)
+	switch input {
+	case inputMnemonic:
+		mnemonic, err = askMnemonic()
+	case inputEntropy:
+		mnemonic, err = askEntropy()
+	default:
+		err = fmt.Errorf("unknown input format")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Entropy is checked via the mnemonic encoding it, which includes
+	// the check for patterned entropy.
+	if err = confirmWeakSecret("mnemonic", bip39.CheckMnemonicStrength(mnemonic)); err != nil {
+		return nil, err
+	}
+
+	return mnemonic, nil
+}
+
+func askMnemonic() ([]byte, error) {
+	var s string
+	if err := survey.AskOne(&survey.Input{
+		Message: "How many words is your mnemonic",
+		Default: "24",
+	}, &s, survey.WithValidator(isMnemonicLength)); err != nil {
+		return nil, err
+	}
+
+	mnemonicLength, _ := strconv.ParseUint(s, 10, 32)
+	for {
+		words := make([]string, 0, int(mnemonicLength))
+		for i := 1; i <= int(mnemonicLength); i++ {
+			if err := survey.AskOne(&survey.Password{
+				Message: fmt.Sprintf("Enter word %d", i),
+			}, &s, survey.WithValidator(isMnemonicWord)); err != nil {
+				return nil, err
+			}
+			words = append(words, s)
+		}
+
+		mnemonic, err := bip39.ValidateAndExpandMnemonic([]byte(strings.Join(words, " ")))
+		if err != nil {
+			fmt.Printf(" Invalid mnemonic: %v\n", err)
+			continue
+		}
+
+		return mnemonic, nil
+	}
+}
+
+func askEntropy() ([]byte, error) {
+	var s string
+	if err := survey.AskOne(&survey.Password{
+		Message: "Enter the entropy (hex)",
+	}, &s, survey.WithValidator(isHexEntropy)); err != nil {
+		return nil, err
+	}
+
+	entropy, _ := hex.DecodeString(strings.TrimSpace(s))
+	return bip39.EntropyToMnemonic(entropy)
+}
+
+// confirmWeakSecret asks the user to confirm the use of a weak wallet
+// secret, if err (from the strength check) is a *bip39.WeakMnemonicError.
+func confirmWeakSecret(what string, err error) error {
+	// Well-known mnemonics have been swept by bots, and anything sent to
+	// them will be lost, so make sure the user knows.
+	var weakErr *bip39.WeakMnemonicError
+	if !errors.As(err, &weakErr) {
+		return nil
+	}
+
+	fmt.Printf(" WARNING:\n")
+	fmt.Printf("\n")
+	fmt.Printf("  The %s is WEAK or PUBLICLY KNOWN, and any funds held by\n", what)
+	fmt.Printf("  accounts derived from it can and WILL BE STOLEN:\n")
+	for _, reason := range weakErr.Reasons {
+		fmt.Printf("   - %s\n", reason)
+	}
+	fmt.Printf("\n")
+
+	var ok bool
+	if err := survey.AskOne(&survey.Confirm{
+		Message: fmt.Sprintf("Continue with the weak %s anyway", what),
+		Default: false,
+	}, &ok); err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("user abort")
+	}
+
+	return nil
+}
+
+func doConvert() error {
+	if err := doSelftest(); err != nil {
+		return err
+	}
+	if err := doAirgapCheck(); err != nil {
+		return err
+	}
+
+	fmt.Printf(" WARNING:\n")
+	fmt.Printf("\n")
+	fmt.Printf("  This will display your wallet secret in every supported format.\n")
+	fmt.Printf("  Anyone with access to any of them controls ALL OF YOUR ACCOUNTS.\n")
+	fmt.Printf("\n")
+
+	var input string
+	if err := survey.AskOne(&survey.Select{
+		Message: "What form is your wallet secret in",
+		Options: []string{inputMnemonic, inputEntropy},
+	}, &input); err != nil {
+		return err
+	}
+
+	mnemonic, err := askMnemonicOrEntropy(input)
+	if err != nil {
+		return err
+	}
+	passphrase, err := askPassphrase()
+	if err != nil {
+		return err
+	}
+	entropy, err := bip39.MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf(" Mnemonic: %s\n", mnemonic)
+	fmt.Printf(" Entropy:  %x\n", entropy)
+	fmt.Printf(" Seed:     %x\n", bip39.MnemonicToSeed(passphrase, mnemonic))
+
+	return nil
+}
+
+func isHexEntropy(val interface{}) error {
+	s := strings.TrimSpace(val.(string))
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex: %v", err)
+	}
+	if _, err = bip39.EntropyToMnemonic(b); err != nil {
+		return fmt.Errorf("invalid entropy length")
+	}
+	return nil
+}
+
+func isHexSeed(val interface{}) error {
+	s := strings.TrimSpace(val.(string))
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return fmt.Errorf("invalid hex: %v", err)
+	}
+	if len(b) != seedSize {
+		return fmt.Errorf("invalid seed length (expected %d bytes)", seedSize)
+	}
+	return nil
+}